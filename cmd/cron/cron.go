@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"flag"
+	"os/signal"
+	"syscall"
 	"yxy-go/internal/config"
 	"yxy-go/internal/cron"
 	"yxy-go/internal/svc"
@@ -26,8 +28,16 @@ func main() {
 	cronJob.MustRegister()
 
 	logx.Info("启动定时服务")
-	ctx.Cron.Start()
-	defer ctx.Cron.Stop()
-
-	select {} // Keep the service running
+	if err := cronJob.Start(); err != nil {
+		panic(err)
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-signalCtx.Done()
+
+	logx.Info("正在停止定时服务")
+	cronJob.Stop()
+	logx.Info("定时服务已停止")
+	_ = logx.Close()
 }
@@ -0,0 +1,91 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yxy-go/internal/logic/bus"
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// announcementNotifiedTTL 同一公告同一内容只推送一次, 记录保留的时长
+const announcementNotifiedTTL = 30 * 24 * time.Hour
+
+// AnnouncementNotifier 有新发布或被编辑的校车公告时, 通知订阅了校车公告的用户
+type AnnouncementNotifier struct {
+	logx.Logger
+	ctx                 context.Context
+	svcCtx              *svc.ServiceContext
+	sender              Sender
+	subscriptionManager *busManager.AnnouncementSubscriptionManager
+}
+
+func NewAnnouncementNotifier(ctx context.Context, svcCtx *svc.ServiceContext) *AnnouncementNotifier {
+	return &AnnouncementNotifier{
+		Logger:              logx.WithContext(ctx),
+		ctx:                 ctx,
+		svcCtx:              svcCtx,
+		sender:              NewDefaultSender(ctx, svcCtx),
+		subscriptionManager: busManager.NewAnnouncementSubscriptionManager(ctx, svcCtx),
+	}
+}
+
+// Notify 作为 GetBusAnnouncementLogic.OnAnnouncementsChanged 的回调
+func (n *AnnouncementNotifier) Notify(changes []bus.AnnouncementChange) {
+	uids, err := n.subscriptionManager.ListAll()
+	if err != nil {
+		n.Logger.Errorf("Query announcement subscribers failed: %v", err)
+		return
+	}
+
+	var sent, skipped, failed int
+	for _, uid := range uids {
+		rule, err := lookupRecipient(n.svcCtx, uid)
+		if err != nil {
+			n.Logger.Errorf("Query recipient of UID %s failed: %v", uid, err)
+			failed++
+			continue
+		}
+		for _, change := range changes {
+			notified, err := n.notify(rule, change)
+			if err != nil {
+				n.Logger.Errorf("Send announcement %s to UID %s failed: %v", change.Announcement.ID, uid, err)
+				failed++
+				continue
+			}
+			if !notified {
+				skipped++
+				continue
+			}
+			sent++
+		}
+	}
+	n.Logger.Infof("Announcement notify finished, changes: %d, subscribers: %d, sent: %d, skipped: %d, failed: %d", len(changes), len(uids), sent, skipped, failed)
+}
+
+// notify 返回是否实际发送; 同一公告同一内容已经推送过时返回 false
+func (n *AnnouncementNotifier) notify(rule *Rule, change bus.AnnouncementChange) (bool, error) {
+	key := fmt.Sprintf("bus:announcement:notified:%s:%s:%s", rule.YxyUID, change.Announcement.ID, change.Hash)
+	acquired, err := n.svcCtx.Rdb.SetNX(n.ctx, key, 1, announcementNotifiedTTL).Result()
+	if err != nil || !acquired {
+		return false, err
+	}
+
+	title := "新校车公告"
+	if change.Type == bus.AnnouncementEdited {
+		title = "校车公告更新"
+	}
+	err = n.sender.Send(rule, Alert{
+		Key:     change.Announcement.ID,
+		Title:   title,
+		Content: change.Announcement.Title,
+	})
+	if err != nil {
+		n.svcCtx.Rdb.Del(n.ctx, key)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package alert
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"yxy-go/internal/logic/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// KindBusAnnouncementKeyword 新的校车公告包含关键字, 参数: {"keyword": "停运"}
+	KindBusAnnouncementKeyword = "bus_announcement_keyword"
+	// KindBusSeatsOpened 指定线路、发车时间的班次余票从 0 变为大于 0, 参数: {"route": "屏峰", "departure_time": "17:30"};
+	// 在更新校车信息时判断, 不受 AlertRule.BusCronTime 控制
+	KindBusSeatsOpened = "bus_seats_opened"
+)
+
+type BusAnnouncementKeywordEvaluator struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewBusAnnouncementKeywordEvaluator(ctx context.Context, svcCtx *svc.ServiceContext) *BusAnnouncementKeywordEvaluator {
+	return &BusAnnouncementKeywordEvaluator{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (e *BusAnnouncementKeywordEvaluator) Source() string {
+	return SourceBus
+}
+
+func (e *BusAnnouncementKeywordEvaluator) Evaluate(rule *Rule) ([]Alert, error) {
+	var params struct {
+		Keyword string `json:"keyword"`
+	}
+	if err := rule.ParseParams(&params); err != nil {
+		return nil, err
+	}
+	if params.Keyword == "" {
+		return nil, fmt.Errorf("keyword of rule %d is empty", rule.ID)
+	}
+
+	// 只检查最新一页公告中规则创建后发布的公告, 已提醒过的公告由发送记录去重;
+	// 发送记录只保留 onceAlertTTL, 更早发布的公告也不再判断
+	since := rule.CreatedAt
+	if cutoff := time.Now().Add(-onceAlertTTL); since.Before(cutoff) {
+		since = cutoff
+	}
+	resp, err := bus.NewGetBusAnnouncementLogic(e.ctx, e.svcCtx).GetBusAnnouncement(&types.GetBusAnnouncementReq{
+		Page:     1,
+		PageSize: 10,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get bus announcement failed: %w", err)
+	}
+
+	var alerts []Alert
+	for _, a := range resp.List {
+		publishedAt, err := bus.ParsePublishedAt(a)
+		if err != nil || publishedAt.Before(since) {
+			continue
+		}
+		if !strings.Contains(a.Title, params.Keyword) && !strings.Contains(a.Abstract, params.Keyword) &&
+			!strings.Contains(strings.Join(a.Content, "\n"), params.Keyword) {
+			continue
+		}
+		sum := md5.Sum([]byte(a.Title + a.PublishedAt))
+		alerts = append(alerts, Alert{
+			Key:     hex.EncodeToString(sum[:]),
+			Once:    true,
+			Title:   "校车公告: " + params.Keyword,
+			Content: a.Title,
+		})
+	}
+	return alerts, nil
+}
+
+// BusSeatsOpenedEvaluator 班次余票从 0 变为大于 0 时触发, 判断的是更新校车信息时产生的出现余票事件
+type BusSeatsOpenedEvaluator struct {
+	openings []bus.SeatOpening
+}
+
+func NewBusSeatsOpenedEvaluator(openings []bus.SeatOpening) *BusSeatsOpenedEvaluator {
+	return &BusSeatsOpenedEvaluator{
+		openings: openings,
+	}
+}
+
+func (e *BusSeatsOpenedEvaluator) Source() string {
+	return SourceBusSeats
+}
+
+func (e *BusSeatsOpenedEvaluator) Evaluate(rule *Rule) ([]Alert, error) {
+	var params struct {
+		Route         string `json:"route"`
+		DepartureTime string `json:"departure_time"`
+	}
+	if err := rule.ParseParams(&params); err != nil {
+		return nil, err
+	}
+
+	var alerts []Alert
+	for _, o := range matchSeatOpenings(e.openings, params.Route, params.DepartureTime) {
+		alerts = append(alerts, Alert{
+			Key:     o.BusID + ":" + o.DepartureTime,
+			Title:   "校车余票提醒",
+			Content: fmt.Sprintf("%s %s 剩余%d座", o.Name, o.DepartureTime, o.RemainSeats),
+		})
+	}
+	return alerts, nil
+}
+
+// SeatsOpenedRuleNotifier 班次出现余票时, 判断全部余票提醒规则
+type SeatsOpenedRuleNotifier struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSeatsOpenedRuleNotifier(ctx context.Context, svcCtx *svc.ServiceContext) *SeatsOpenedRuleNotifier {
+	return &SeatsOpenedRuleNotifier{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Notify 作为 GetBusInfoLogic.OnSeatsOpened 的回调, 未开启提醒规则时跳过
+func (n *SeatsOpenedRuleNotifier) Notify(openings []bus.SeatOpening) {
+	if !n.svcCtx.Config.AlertRule.EnableCron || n.svcCtx.DB == nil {
+		return
+	}
+	e := NewEngine(n.ctx, n.svcCtx, NewDefaultSender(n.ctx, n.svcCtx))
+	e.Register(KindBusSeatsOpened, NewBusSeatsOpenedEvaluator(openings))
+	if _, err := e.Run(SourceBusSeats); err != nil {
+		n.Logger.Errorf("Evaluate seats opened rules failed: %v", err)
+	}
+}
+
+// matchSeatOpenings 筛选线路名称和发车时间匹配的班次
+func matchSeatOpenings(openings []bus.SeatOpening, route, departureTime string) []bus.SeatOpening {
+	var matched []bus.SeatOpening
+	for _, o := range openings {
+		if strings.Contains(o.Name, route) && strings.Contains(o.DepartureTime, departureTime) {
+			matched = append(matched, o)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,40 @@
+package alert
+
+import (
+	"testing"
+	"yxy-go/internal/logic/bus"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSeatOpenings(t *testing.T) {
+	openings := []bus.SeatOpening{
+		{BusID: "1", Name: "朝晖-屏峰", DepartureTime: "2025-03-03 07:30", RemainSeats: 2},
+		{BusID: "1", Name: "朝晖-屏峰", DepartureTime: "2025-03-03 17:30", RemainSeats: 1},
+		{BusID: "2", Name: "屏峰-莫干山", DepartureTime: "2025-03-03 17:30", RemainSeats: 5},
+	}
+
+	matched := matchSeatOpenings(openings, "朝晖", "17:30")
+	if assert.Len(t, matched, 1) {
+		assert.Equal(t, "1", matched[0].BusID)
+	}
+	assert.Len(t, matchSeatOpenings(openings, "屏峰", ""), 3)
+	assert.Empty(t, matchSeatOpenings(nil, "屏峰", "17:30"))
+}
+
+func TestBusSeatsOpenedEvaluator(t *testing.T) {
+	e := NewBusSeatsOpenedEvaluator([]bus.SeatOpening{
+		{BusID: "1", Name: "朝晖-屏峰", DepartureTime: "2025-03-03 17:30", RemainSeats: 1},
+	})
+
+	alerts, err := e.Evaluate(&Rule{ID: 1, Params: `{"route": "屏峰", "departure_time": "17:30"}`})
+	assert.NoError(t, err)
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, "1:2025-03-03 17:30", alerts[0].Key)
+		assert.False(t, alerts[0].Once)
+	}
+
+	alerts, err = e.Evaluate(&Rule{ID: 2, Params: `{"route": "莫干山"}`})
+	assert.NoError(t, err)
+	assert.Empty(t, alerts)
+}
@@ -0,0 +1,67 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yxy-go/internal/logic/card"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/shopspring/decimal"
+)
+
+// KindCardDailySpend 当天校园卡消费超过阈值, 参数: {"threshold": 100}
+const KindCardDailySpend = "card_daily_spend"
+
+type CardDailySpendEvaluator struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCardDailySpendEvaluator(ctx context.Context, svcCtx *svc.ServiceContext) *CardDailySpendEvaluator {
+	return &CardDailySpendEvaluator{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (e *CardDailySpendEvaluator) Source() string {
+	return SourceCard
+}
+
+func (e *CardDailySpendEvaluator) Evaluate(rule *Rule) ([]Alert, error) {
+	var params struct {
+		Threshold float64 `json:"threshold"`
+	}
+	if err := rule.ParseParams(&params); err != nil {
+		return nil, err
+	}
+
+	today := time.Now().Format("20060102")
+	resp, err := card.NewGetCardTransactionsLogic(e.ctx, e.svcCtx).GetCardTransactions(&types.GetCardTransactionsReq{
+		UID:       rule.YxyUID,
+		QueryTime: today,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get card transactions failed: %w", err)
+	}
+
+	spent := decimal.Zero
+	for _, s := range resp.Summary {
+		if s.Type == card.TransactionConsume {
+			if spent, err = decimal.NewFromString(s.Total); err != nil {
+				return nil, fmt.Errorf("parse card spending failed: %w", err)
+			}
+		}
+	}
+	threshold := decimal.NewFromFloat(params.Threshold)
+	if spent.LessThanOrEqual(threshold) {
+		return nil, nil
+	}
+	return []Alert{{
+		Key:     today,
+		Title:   "今日校园卡消费提醒",
+		Content: fmt.Sprintf("今日已消费%s元, 超过%s元", spent.StringFixed(2), threshold.StringFixed(2)),
+	}}, nil
+}
@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"yxy-go/internal/logic/electricity"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+)
+
+// KindElectricityUsageSpike 最近一天用电量超过之前若干天平均值的倍数,
+// 参数: {"campus": "zhpf", "room_str_concat": "...", "multiplier": 2, "days": 7}
+const KindElectricityUsageSpike = "electricity_usage_spike"
+
+type ElectricityUsageSpikeEvaluator struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewElectricityUsageSpikeEvaluator(ctx context.Context, svcCtx *svc.ServiceContext) *ElectricityUsageSpikeEvaluator {
+	return &ElectricityUsageSpikeEvaluator{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (e *ElectricityUsageSpikeEvaluator) Source() string {
+	return SourceElectricity
+}
+
+func (e *ElectricityUsageSpikeEvaluator) Evaluate(rule *Rule) ([]Alert, error) {
+	params := struct {
+		Campus        string  `json:"campus"`
+		RoomStrConcat string  `json:"room_str_concat"`
+		Multiplier    float64 `json:"multiplier"`
+		Days          int     `json:"days"`
+	}{Multiplier: 2, Days: 7}
+	if err := rule.ParseParams(&params); err != nil {
+		return nil, err
+	}
+
+	resp, err := electricity.NewGetElectricityUsageRecordsLogic(e.ctx, e.svcCtx).GetElectricityUsageRecords(&types.GetElectricityUsageRecordsReq{
+		Uid:           rule.YxyUID,
+		Campus:        params.Campus,
+		RoomStrConcat: params.RoomStrConcat,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get electricity usage records failed: %w", err)
+	}
+
+	date, usage, average, ok := detectUsageSpike(resp.List, params.Days, params.Multiplier)
+	if !ok {
+		return nil, nil
+	}
+	return []Alert{{
+		Key:     date,
+		Title:   "寝室用电量异常提醒",
+		Content: fmt.Sprintf("%s用电%.2f度, 近期日均%.2f度", date, usage, average),
+	}}, nil
+}
+
+// detectUsageSpike 判断最近一天的用电量是否超过之前 days 天平均值的 multiplier 倍
+func detectUsageSpike(records []types.ElectricityUsageRecord, days int, multiplier float64) (date string, usage, average float64, ok bool) {
+	type dailyUsage struct {
+		date  string
+		usage float64
+	}
+	var usages []dailyUsage
+	for _, record := range records {
+		value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(record.Usage), "度"), 64)
+		if err != nil {
+			continue
+		}
+		usages = append(usages, dailyUsage{date: record.Datetime, usage: value})
+	}
+	if len(usages) < 2 {
+		return "", 0, 0, false
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].date > usages[j].date
+	})
+
+	history := usages[1:]
+	if len(history) > days {
+		history = history[:days]
+	}
+	var total float64
+	for _, u := range history {
+		total += u.usage
+	}
+	average = total / float64(len(history))
+	latest := usages[0]
+	return latest.date, latest.usage, average, average > 0 && latest.usage > average*multiplier
+}
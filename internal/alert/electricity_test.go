@@ -0,0 +1,33 @@
+package alert
+
+import (
+	"testing"
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectUsageSpike(t *testing.T) {
+	records := []types.ElectricityUsageRecord{
+		{Usage: "3.00度", Datetime: "2024-09-01"},
+		{Usage: "2.00度", Datetime: "2024-09-02"},
+		{Usage: "9.50度", Datetime: "2024-09-04"},
+		{Usage: "4.00度", Datetime: "2024-09-03"},
+	}
+
+	date, usage, average, ok := detectUsageSpike(records, 7, 2)
+	assert.True(t, ok)
+	assert.Equal(t, "2024-09-04", date)
+	assert.Equal(t, 9.5, usage)
+	assert.Equal(t, 3.0, average)
+
+	_, _, _, ok = detectUsageSpike(records, 7, 4)
+	assert.False(t, ok)
+
+	// 只取最近的 days 天计算平均值
+	_, _, average, _ = detectUsageSpike(records, 1, 2)
+	assert.Equal(t, 4.0, average)
+
+	_, _, _, ok = detectUsageSpike(records[:1], 7, 2)
+	assert.False(t, ok)
+}
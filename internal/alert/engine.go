@@ -0,0 +1,184 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"yxy-go/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Engine 按数据来源批量判断提醒规则, 对触发的提醒去重后发送
+type Engine struct {
+	logx.Logger
+	ctx        context.Context
+	svcCtx     *svc.ServiceContext
+	evaluators map[string]Evaluator
+	sender     Sender
+}
+
+func NewEngine(ctx context.Context, svcCtx *svc.ServiceContext, sender Sender) *Engine {
+	return &Engine{
+		Logger:     logx.WithContext(ctx),
+		ctx:        ctx,
+		svcCtx:     svcCtx,
+		evaluators: make(map[string]Evaluator),
+		sender:     sender,
+	}
+}
+
+// NewDefaultEngine 注册由定时任务判断的内置规则, 余票提醒规则由 SeatsOpenedRuleNotifier 判断
+func NewDefaultEngine(ctx context.Context, svcCtx *svc.ServiceContext) *Engine {
+	e := NewEngine(ctx, svcCtx, NewDefaultSender(ctx, svcCtx))
+	e.Register(KindCardDailySpend, NewCardDailySpendEvaluator(ctx, svcCtx))
+	e.Register(KindElectricityUsageSpike, NewElectricityUsageSpikeEvaluator(ctx, svcCtx))
+	e.Register(KindBusAnnouncementKeyword, NewBusAnnouncementKeywordEvaluator(ctx, svcCtx))
+	return e
+}
+
+// Register 注册规则类型对应的 Evaluator
+func (e *Engine) Register(kind string, evaluator Evaluator) {
+	e.evaluators[kind] = evaluator
+}
+
+// Sources 已注册规则涉及的数据来源
+func (e *Engine) Sources() []string {
+	set := make(map[string]struct{})
+	for _, evaluator := range e.evaluators {
+		set[evaluator.Source()] = struct{}{}
+	}
+	sources := make([]string, 0, len(set))
+	for source := range set {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func (e *Engine) kindsOf(source string) []string {
+	var kinds []string
+	for kind, evaluator := range e.evaluators {
+		if evaluator.Source() == source {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// RunStats 规则判断统计
+type RunStats struct {
+	Total         int `json:"total"`          // 规则总数
+	ProcessFailed int `json:"process_failed"` // 判断过程中出错的规则数
+	Triggered     int `json:"triggered"`      // 触发的提醒数
+	Cooldown      int `json:"cooldown"`       // 冷却中或已发送过而跳过的提醒数
+	SendSuccess   int `json:"send_success"`   // 发送成功的提醒数
+	SendFailed    int `json:"send_failed"`    // 发送失败的提醒数
+}
+
+// Run 判断某一数据来源下的全部规则
+func (e *Engine) Run(source string) (RunStats, error) {
+	var stats RunStats
+
+	kinds := e.kindsOf(source)
+	if len(kinds) == 0 {
+		return stats, nil
+	}
+
+	// 按规则 ID 分页, 执行期间新增或停用规则不会导致漏判
+	var lastID int64
+	pageSize := 100
+	for {
+		rules, err := e.queryRulesAfter(kinds, lastID, pageSize)
+		if err != nil {
+			e.Logger.Errorf("Query alert rules failed: %v", err)
+			return stats, err
+		}
+		if len(rules) == 0 {
+			break
+		}
+		lastID = rules[len(rules)-1].ID
+		stats.Total += len(rules)
+
+		for i := range rules {
+			// 定时服务退出时停止判断剩余的规则
+			if err := e.ctx.Err(); err != nil {
+				e.logStats(source, stats)
+				return stats, err
+			}
+			rule := &rules[i]
+			alerts, err := e.evaluators[rule.Kind].Evaluate(rule)
+			if err != nil {
+				stats.ProcessFailed++
+				e.Logger.Errorf("Evaluate rule %d (%s) for user ID %d failed: %v", rule.ID, rule.Kind, rule.UserID, err)
+				continue
+			}
+			for _, alert := range alerts {
+				stats.Triggered++
+				acquired, err := e.acquireCooldown(rule, alert)
+				if err != nil {
+					stats.ProcessFailed++
+					e.Logger.Errorf("Acquire cooldown for rule %d failed: %v", rule.ID, err)
+					continue
+				}
+				if !acquired {
+					stats.Cooldown++
+					continue
+				}
+				if err = e.sender.Send(rule, alert); err != nil {
+					stats.SendFailed++
+					e.releaseCooldown(rule, alert)
+					e.Logger.Errorf("Send alert of rule %d to user ID %d (OpenID: %s) failed: %v", rule.ID, rule.UserID, rule.OpenID, err)
+					continue
+				}
+				stats.SendSuccess++
+			}
+		}
+	}
+	e.logStats(source, stats)
+	return stats, nil
+}
+
+func (e *Engine) logStats(source string, stats RunStats) {
+	e.Logger.Infof("Alert rule statistics (%s): Total=%d, ProcessFailed=%d, Triggered=%d, Cooldown=%d, SendSuccess=%d, SendFailed=%d",
+		source, stats.Total, stats.ProcessFailed, stats.Triggered, stats.Cooldown, stats.SendSuccess, stats.SendFailed)
+}
+
+// queryRulesAfter 按 ID 顺序获取 lastID 之后开启的规则
+func (e *Engine) queryRulesAfter(kinds []string, lastID int64, pageSize int) ([]Rule, error) {
+	var rules []Rule
+	err := e.svcCtx.DB.Table("alert_rules ar").
+		Select("ar.id, ar.user_id, ar.kind, ar.params, ar.cooldown, ar.created_at, u.wechat_open_id as openid, u.yxy_uid as yxy_uid").
+		Joins("JOIN users u ON ar.user_id = u.id").
+		Where("ar.enabled = ? AND ar.kind IN ? AND ar.id > ?", true, kinds, lastID).
+		Order("ar.id").
+		Limit(pageSize).
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// onceAlertTTL 只发送一次的提醒的发送记录保留时间, 产生这类提醒的 Evaluator 只判断这段时间内的数据
+const onceAlertTTL = 30 * 24 * time.Hour
+
+// getCooldownKey 每个规则的每个提醒一个 key, 过期后可以再次发送
+func (e *Engine) getCooldownKey(rule *Rule, alert Alert) string {
+	return fmt.Sprintf("alert:cooldown:%d:%s", rule.ID, alert.Key)
+}
+
+// acquireCooldown 记录提醒发送时间, 冷却时间内已发送过时返回 false; 只发送一次的提醒记录保留 onceAlertTTL
+func (e *Engine) acquireCooldown(rule *Rule, alert Alert) (bool, error) {
+	ttl := rule.cooldown(e.svcCtx.Config.AlertRule.DefaultCooldown)
+	if alert.Once {
+		ttl = onceAlertTTL
+	}
+	return e.svcCtx.Rdb.SetNX(e.ctx, e.getCooldownKey(rule, alert), 1, ttl).Result()
+}
+
+// releaseCooldown 发送失败时清除记录, 下次判断时重新发送
+func (e *Engine) releaseCooldown(rule *Rule, alert Alert) {
+	e.svcCtx.Rdb.Del(e.ctx, e.getCooldownKey(rule, alert))
+}
@@ -0,0 +1,17 @@
+package alert
+
+const (
+	SourceCard        = "card"
+	SourceElectricity = "electricity"
+	SourceBus         = "bus"
+	// SourceBusSeats 由更新校车信息时出现余票的事件触发, 不单独注册定时任务
+	SourceBusSeats = "bus_seats"
+)
+
+// Evaluator 某一类规则的判断逻辑
+type Evaluator interface {
+	// Source 规则依赖的数据来源, 同一来源的规则在同一个定时任务中判断
+	Source() string
+	// Evaluate 判断规则是否触发, 未触发时返回空列表
+	Evaluate(rule *Rule) ([]Alert, error)
+}
@@ -0,0 +1,66 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Rule 用户配置的提醒规则, 通过管理接口创建, 表结构:
+//
+//	CREATE TABLE alert_rules (
+//	    id         BIGINT PRIMARY KEY AUTO_INCREMENT,
+//	    user_id    BIGINT      NOT NULL,
+//	    kind       VARCHAR(64) NOT NULL,
+//	    params     TEXT        NOT NULL,
+//	    cooldown   BIGINT      NOT NULL DEFAULT 0, -- 秒, 为 0 时使用默认值
+//	    enabled    TINYINT(1)  NOT NULL DEFAULT 1,
+//	    created_at DATETIME    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+//	    KEY idx_user (user_id),
+//	    KEY idx_kind (kind, enabled)
+//	);
+type Rule struct {
+	ID        int64     `gorm:"column:id"`
+	UserID    int64     `gorm:"column:user_id"`
+	OpenID    string    `gorm:"column:openid"`
+	YxyUID    string    `gorm:"column:yxy_uid"`
+	Kind      string    `gorm:"column:kind"`
+	Params    string    `gorm:"column:params"`   // JSON 格式的规则参数, 由对应的 Evaluator 解析
+	Cooldown  int64     `gorm:"column:cooldown"` // 同一提醒的冷却时间(秒), 为 0 时使用默认值
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// ValidKind 是否为支持的规则类型
+func ValidKind(kind string) bool {
+	switch kind {
+	case KindCardDailySpend, KindElectricityUsageSpike, KindBusAnnouncementKeyword, KindBusSeatsOpened:
+		return true
+	}
+	return false
+}
+
+// Alert 规则触发后产生的提醒
+type Alert struct {
+	Key     string // 去重标识, 同一规则相同 Key 的提醒在冷却时间内只发送一次
+	Once    bool   // 同一规则相同 Key 的提醒只发送一次, 不受冷却时间限制
+	Title   string
+	Content string
+}
+
+// ParseParams 解析规则参数
+func (r *Rule) ParseParams(v any) error {
+	if r.Params == "" {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(r.Params), v); err != nil {
+		return fmt.Errorf("invalid params of rule %d: %w", r.ID, err)
+	}
+	return nil
+}
+
+func (r *Rule) cooldown(defaultCooldown time.Duration) time.Duration {
+	if r.Cooldown > 0 {
+		return time.Duration(r.Cooldown) * time.Second
+	}
+	return defaultCooldown
+}
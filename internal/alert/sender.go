@@ -0,0 +1,85 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"yxy-go/internal/svc"
+
+	"github.com/ArtisanCloud/PowerWeChat/v3/src/basicService/subscribeMessage/request"
+	"github.com/ArtisanCloud/PowerWeChat/v3/src/kernel/power"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Sender 发送提醒
+type Sender interface {
+	Send(rule *Rule, alert Alert) error
+}
+
+// NewDefaultSender 配置了订阅消息模板时通过小程序发送, 否则只打印日志
+func NewDefaultSender(ctx context.Context, svcCtx *svc.ServiceContext) Sender {
+	if svcCtx.MiniProgram != nil && svcCtx.Config.AlertRule.TemplateID != "" {
+		return NewMiniProgramSender(ctx, svcCtx)
+	}
+	return NewLogSender(ctx)
+}
+
+// LogSender 只打印日志, 用于未配置小程序时调试规则
+type LogSender struct {
+	logx.Logger
+}
+
+func NewLogSender(ctx context.Context) *LogSender {
+	return &LogSender{Logger: logx.WithContext(ctx)}
+}
+
+func (s *LogSender) Send(rule *Rule, alert Alert) error {
+	s.Logger.Infof("Alert for rule %d (user ID %d): %s - %s", rule.ID, rule.UserID, alert.Title, alert.Content)
+	return nil
+}
+
+// MiniProgramSender 通过小程序订阅消息发送提醒
+type MiniProgramSender struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewMiniProgramSender(ctx context.Context, svcCtx *svc.ServiceContext) *MiniProgramSender {
+	return &MiniProgramSender{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (s *MiniProgramSender) Send(rule *Rule, alert Alert) error {
+	mpResp, err := s.svcCtx.MiniProgram.SubscribeMessage.Send(s.ctx, &request.RequestSubscribeMessageSend{
+		ToUser:           rule.OpenID,
+		TemplateID:       s.svcCtx.Config.AlertRule.TemplateID,
+		Page:             "/pages/index/index",
+		MiniProgramState: s.svcCtx.Config.LowBattery.MiniProgram.State,
+		Lang:             "zh_CN",
+		Data: &power.HashMap{
+			"thing1": power.StringMap{ // 提醒标题
+				"value": truncate(alert.Title, 20),
+			},
+			"thing2": power.StringMap{ // 提醒内容
+				"value": truncate(alert.Content, 20),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if mpResp.ErrCode != 0 {
+		return fmt.Errorf("errcode: %d, errmsg: %s", mpResp.ErrCode, mpResp.ErrMsg)
+	}
+	return nil
+}
+
+// truncate 订阅消息 thing 类型最多20个字符
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
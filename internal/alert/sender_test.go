@@ -0,0 +1,12 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "今日校园卡消费提醒", truncate("今日校园卡消费提醒", 20))
+	assert.Equal(t, "一二三…", truncate("一二三四五", 4))
+}
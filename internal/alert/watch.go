@@ -0,0 +1,105 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"yxy-go/internal/logic/bus"
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// seatWatchCooldown 余票反复变化时, 同一班次的提醒间隔
+const seatWatchCooldown = 10 * time.Minute
+
+// SeatWatchNotifier 班次出现余票时, 通知设置了对应余票提醒的用户
+type SeatWatchNotifier struct {
+	logx.Logger
+	ctx          context.Context
+	svcCtx       *svc.ServiceContext
+	sender       Sender
+	watchManager *busManager.SeatWatchManager
+}
+
+func NewSeatWatchNotifier(ctx context.Context, svcCtx *svc.ServiceContext) *SeatWatchNotifier {
+	return &SeatWatchNotifier{
+		Logger:       logx.WithContext(ctx),
+		ctx:          ctx,
+		svcCtx:       svcCtx,
+		sender:       NewDefaultSender(ctx, svcCtx),
+		watchManager: busManager.NewSeatWatchManager(ctx, svcCtx),
+	}
+}
+
+// Notify 作为 GetBusInfoLogic.OnSeatsOpened 的回调
+func (n *SeatWatchNotifier) Notify(openings []bus.SeatOpening) {
+	watches, err := n.watchManager.ListAll()
+	if err != nil {
+		n.Logger.Errorf("Query seat watches failed: %v", err)
+		return
+	}
+
+	for _, opening := range openings {
+		for _, watch := range watches {
+			if watch.BusID != opening.BusID || !matchDepartureTime(opening.DepartureTime, watch.DepartureTime) {
+				continue
+			}
+			if err = n.notify(watch, opening); err != nil {
+				n.Logger.Errorf("Send seat watch %s to UID %s failed: %v", watch.ID, watch.UID, err)
+			}
+		}
+	}
+}
+
+func (n *SeatWatchNotifier) notify(watch busManager.SeatWatch, opening bus.SeatOpening) error {
+	// 找不到接收人时跳过该提醒, 不占用冷却时间
+	rule, err := lookupRecipient(n.svcCtx, watch.UID)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("bus:seat_watch:notified:%s:%s", watch.ID, opening.DepartureTime)
+	acquired, err := n.svcCtx.Rdb.SetNX(n.ctx, key, 1, seatWatchCooldown).Result()
+	if err != nil || !acquired {
+		return err
+	}
+
+	err = n.sender.Send(rule, Alert{
+		Key:     watch.ID,
+		Title:   "校车余票提醒",
+		Content: fmt.Sprintf("%s %s 剩余%d座", opening.Name, opening.DepartureTime, opening.RemainSeats),
+	})
+	if err != nil {
+		n.svcCtx.Rdb.Del(n.ctx, key)
+	}
+	return err
+}
+
+// matchDepartureTime 判断班次的发车时间(完整日期时间)是否为提醒设置的 15:04
+func matchDepartureTime(departure, clock string) bool {
+	return strings.Contains(departure, " "+clock) || strings.Contains(departure, "T"+clock)
+}
+
+// lookupRecipient 根据易校园 UID 查找用户的 openid; 未启用数据库或用户未绑定微信时返回错误, 不发送提醒
+func lookupRecipient(svcCtx *svc.ServiceContext, uid string) (*Rule, error) {
+	if svcCtx.DB == nil {
+		return nil, errors.New("database is not configured")
+	}
+	rule := &Rule{YxyUID: uid}
+	err := svcCtx.DB.Table("users").
+		Select("id as user_id, wechat_open_id as openid").
+		Where("yxy_uid = ?", uid).
+		Limit(1).
+		Find(rule).Error
+	if err != nil {
+		return nil, err
+	}
+	if rule.OpenID == "" {
+		return nil, fmt.Errorf("no openid for UID %s", uid)
+	}
+	return rule, nil
+}
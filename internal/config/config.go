@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/zeromicro/go-zero/rest"
 )
 
@@ -31,13 +33,63 @@ type Config struct {
 			State        string
 			TemplateID   string
 		}
-		EnableCron bool
-		CronTime   string
+		EnableCron   bool
+		CronTime     string
+		ResumeWindow time.Duration `json:",default=12h"` // 短于每天一次的执行间隔, 按计划执行时总是新建运行
+	}
+	LowCardBalance struct {
+		EnableCron       bool   `json:",optional"`
+		CronTime         string `json:",optional"`
+		TemplateID       string `json:",optional"`
+		MiniProgramState string `json:",default=formal"`
+	}
+	AlertRule struct {
+		EnableCron          bool          `json:",optional"`
+		CardCronTime        string        `json:",optional"`
+		ElectricityCronTime string        `json:",optional"`
+		BusCronTime         string        `json:",optional"`
+		DefaultCooldown     time.Duration `json:",default=24h"`
+		TemplateID          string        `json:",optional"`
+	}
+	Card struct {
+		QueryConcurrency int `json:",default=4"`
+		MaxQueryDays     int `json:",default=62"`
+		MealTime         struct {
+			Breakfast string `json:",default=06:00-10:00"`
+			Lunch     string `json:",default=10:30-13:30"`
+			Dinner    string `json:",default=16:30-19:30"`
+		}
+		AnomalyMultiplier  float64 `json:",default=3"`
+		WalletLookbackDays int     `json:",default=14"`
+		Wallets            []struct {
+			WalletNo string
+			Name     string `json:",optional"`
+		} `json:",optional"`
 	}
 	BusService struct {
-		UID                     string
+		UID                     string `json:",optional"`
 		MaxRetries              int
 		BusInfoCronTime         string
 		BusAnnouncementCronTime string
+		MaxSeatWatches          int           `json:",default=10"`
+		CrawlConcurrency        int           `json:",default=8"`
+		CrawlRateLimit          float64       `json:",default=10"`
+		CrawlBurst              int           `json:",default=5"`
+		ChangeRetention         time.Duration `json:",default=72h"`
+		AnnouncementRetention   time.Duration `json:",default=720h"`
+		SearchUpstreamFallback  bool          `json:",optional"`
+		UIDs                    []string      `json:",optional"`
+		UIDQuarantine           time.Duration `json:",default=30m"`
+		InfoMaxAge              time.Duration `json:",default=5m"`
+		AnnouncementMaxAge      time.Duration `json:",default=3h"`
+	}
+	Admin struct {
+		Token string `json:",optional"`
+	}
+	Job struct {
+		HistorySize     int           `json:",default=50"`
+		DefaultTimeout  time.Duration `json:",default=30m"`
+		LeaderLease     time.Duration `json:",default=30s"`
+		ShutdownTimeout time.Duration `json:",default=30s"`
 	}
 }
@@ -0,0 +1,171 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"yxy-go/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stringx"
+)
+
+const leaderCacheKey = "cron:leader"
+
+// 仅当租约仍属于当前实例时续期或释放
+var (
+	renewLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLeaderScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// LeaderElector 基于 redis 租约选主, 多个定时服务实例中只有主节点执行任务;
+// 主节点退出或无法续期时租约过期, 其他实例在下一次竞选时接替
+type LeaderElector struct {
+	logx.Logger
+	ctx       context.Context
+	svcCtx    *svc.ServiceContext
+	id        string
+	leader    atomic.Bool
+	onElected func()
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	// 当前任期的 context, 失去主节点时取消, 使执行中的任务随之停止
+	mu         sync.Mutex
+	termCtx    context.Context
+	cancelTerm context.CancelFunc
+	renewedAt  time.Time
+}
+
+func NewLeaderElector(ctx context.Context, svcCtx *svc.ServiceContext) *LeaderElector {
+	hostname, _ := os.Hostname()
+	termCtx, cancelTerm := context.WithCancel(ctx)
+	cancelTerm()
+	return &LeaderElector{
+		Logger:     logx.WithContext(ctx),
+		ctx:        ctx,
+		svcCtx:     svcCtx,
+		id:         fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), stringx.Randn(6)),
+		stop:       make(chan struct{}),
+		termCtx:    termCtx,
+		cancelTerm: cancelTerm,
+	}
+}
+
+// OnElected 设置成为主节点时的回调
+func (e *LeaderElector) OnElected(fn func()) {
+	e.onElected = fn
+}
+
+// IsLeader 当前实例是否为主节点
+func (e *LeaderElector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Term 当前任期的 context, 失去主节点时被取消; 不是主节点时返回已取消的 context
+func (e *LeaderElector) Term() context.Context {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.termCtx
+}
+
+// Start 立即竞选一次, 之后按租约的 1/3 周期续期或竞选
+func (e *LeaderElector) Start() {
+	e.campaign()
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.svcCtx.Config.Job.LeaderLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				e.campaign()
+			}
+		}
+	}()
+}
+
+// Stop 停止竞选, 是主节点时释放租约以便其他实例尽快接替
+func (e *LeaderElector) Stop() {
+	close(e.stop)
+	e.wg.Wait()
+	if !e.leader.Load() {
+		return
+	}
+	e.demote()
+	if err := releaseLeaderScript.Run(e.ctx, e.svcCtx.Rdb, []string{leaderCacheKey}, e.id).Err(); err != nil {
+		e.Logger.Errorf("释放定时服务主节点租约失败: %v", err)
+		return
+	}
+	e.Logger.Infof("定时服务主节点已释放: %s", e.id)
+}
+
+// campaign 主节点续期租约, 其他实例尝试获取租约;
+// 续期时 redis 异常会在租约过期前重试, 租约可能已过期时主动放弃主节点, 宁可漏执行也不重复执行
+func (e *LeaderElector) campaign() {
+	lease := e.svcCtx.Config.Job.LeaderLease
+	if e.leader.Load() {
+		renewed, err := renewLeaderScript.Run(e.ctx, e.svcCtx.Rdb, []string{leaderCacheKey}, e.id, lease.Milliseconds()).Int()
+		if err == nil && renewed == 1 {
+			e.renewedAt = time.Now()
+			return
+		}
+		// 下一次续期前租约不会过期时保持主节点, 等待下一次重试
+		if err != nil && time.Since(e.renewedAt)+lease/3 < lease {
+			e.Logger.Errorf("定时服务主节点续期失败, 稍后重试: %s, err: %v", e.id, err)
+			return
+		}
+		e.demote()
+		e.Logger.Errorf("定时服务主节点续期失败, 切换为从节点: %s, err: %v", e.id, err)
+		return
+	}
+
+	acquired, err := e.svcCtx.Rdb.SetNX(e.ctx, leaderCacheKey, e.id, lease).Result()
+	if err != nil {
+		e.Logger.Errorf("竞选定时服务主节点失败: %v", err)
+		return
+	}
+	if !acquired {
+		// 续期失败后租约仍属于当前实例时, 续期并重新成为主节点
+		renewed, err := renewLeaderScript.Run(e.ctx, e.svcCtx.Rdb, []string{leaderCacheKey}, e.id, lease.Milliseconds()).Int()
+		if err != nil || renewed != 1 {
+			return
+		}
+	}
+	e.elect()
+	e.Logger.Infof("成为定时服务主节点: %s", e.id)
+	if e.onElected != nil {
+		e.onElected()
+	}
+}
+
+// elect 开始新的任期
+func (e *LeaderElector) elect() {
+	e.mu.Lock()
+	e.termCtx, e.cancelTerm = context.WithCancel(e.ctx)
+	e.mu.Unlock()
+	e.renewedAt = time.Now()
+	e.leader.Store(true)
+}
+
+// demote 结束当前任期, 取消执行中的任务
+func (e *LeaderElector) demote() {
+	e.leader.Store(false)
+	e.mu.Lock()
+	e.cancelTerm()
+	e.mu.Unlock()
+}
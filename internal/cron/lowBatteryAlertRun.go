@@ -0,0 +1,169 @@
+package cron
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 低电量提醒的运行记录和发送记录, 表结构:
+//
+//	CREATE TABLE low_battery_alert_runs (
+//	    id          BIGINT PRIMARY KEY AUTO_INCREMENT,
+//	    status      VARCHAR(16) NOT NULL,
+//	    cursor_id   BIGINT      NOT NULL DEFAULT 0, -- 已处理到的订阅 ID
+//	    started_at  DATETIME    NOT NULL,
+//	    finished_at DATETIME    NULL
+//	);
+//
+//	CREATE TABLE low_battery_alert_sends (
+//	    id              BIGINT PRIMARY KEY AUTO_INCREMENT,
+//	    run_id          BIGINT   NOT NULL,
+//	    subscription_id BIGINT   NOT NULL,
+//	    created_at      DATETIME NOT NULL,
+//	    UNIQUE KEY uk_run_subscription (run_id, subscription_id)
+//	);
+const (
+	alertRunsTable          = "low_battery_alert_runs"
+	alertSendsTable         = "low_battery_alert_sends"
+	alertSubscriptionsTable = "low_battery_alert_subscriptions"
+)
+
+// 运行状态
+const (
+	alertRunRunning   = "running"
+	alertRunFinished  = "finished"
+	alertRunAbandoned = "abandoned" // 超过恢复时限仍未结束, 不再继续
+)
+
+// AlertRun 一次低电量提醒运行, 中途退出的运行在下次执行时从 CursorID 之后继续
+type AlertRun struct {
+	ID         int64      `gorm:"column:id"`
+	Status     string     `gorm:"column:status"`
+	CursorID   int64      `gorm:"column:cursor_id"`
+	StartedAt  time.Time  `gorm:"column:started_at"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+}
+
+// AlertSend 订阅在某次运行中的发送记录
+type AlertSend struct {
+	ID             int64     `gorm:"column:id"`
+	RunID          int64     `gorm:"column:run_id"`
+	SubscriptionID int64     `gorm:"column:subscription_id"`
+	CreatedAt      time.Time `gorm:"column:created_at"`
+}
+
+// resumable 未结束且在恢复时限内开始的运行可以继续执行
+func (r *AlertRun) resumable(now time.Time, window time.Duration) bool {
+	return r.Status == alertRunRunning && now.Sub(r.StartedAt) <= window
+}
+
+// errSendSkipped 已有发送记录或订阅次数已用完, 用于回滚事务
+var errSendSkipped = errors.New("send skipped")
+
+// startRun 继续最近一次未结束的运行, 没有可继续的运行时新建一次
+func (l *SendLowBatteryAlertLogic) startRun() (*AlertRun, error) {
+	now := time.Now()
+	var run AlertRun
+	err := l.svcCtx.DB.Table(alertRunsTable).
+		Where("status = ?", alertRunRunning).
+		Order("id DESC").
+		Take(&run).Error
+	switch {
+	case err == nil && run.resumable(now, l.svcCtx.Config.LowBattery.ResumeWindow):
+		l.Logger.Infof("Resume low battery alert run %d after subscription ID %d", run.ID, run.CursorID)
+		return &run, nil
+	case err == nil:
+		err = l.svcCtx.DB.Table(alertRunsTable).
+			Where("status = ?", alertRunRunning).
+			Updates(map[string]any{"status": alertRunAbandoned, "finished_at": now}).Error
+		if err != nil {
+			return nil, err
+		}
+		l.Logger.Errorf("Abandon low battery alert run %d started at %s", run.ID, run.StartedAt.Format("2006-01-02 15:04:05"))
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	run = AlertRun{Status: alertRunRunning, StartedAt: now}
+	if err = l.svcCtx.DB.Table(alertRunsTable).Create(&run).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// saveCursor 保存运行已处理到的订阅 ID
+func (l *SendLowBatteryAlertLogic) saveCursor(run *AlertRun) error {
+	return l.svcCtx.DB.Table(alertRunsTable).
+		Where("id = ?", run.ID).
+		Update("cursor_id", run.CursorID).Error
+}
+
+// finishRun 标记运行结束
+func (l *SendLowBatteryAlertLogic) finishRun(run *AlertRun) error {
+	return l.svcCtx.DB.Table(alertRunsTable).
+		Where("id = ?", run.ID).
+		Updates(map[string]any{"status": alertRunFinished, "cursor_id": run.CursorID, "finished_at": time.Now()}).Error
+}
+
+// sentSubscriptions 获取本次运行中已有发送记录的订阅
+func (l *SendLowBatteryAlertLogic) sentSubscriptions(runID int64, ids []int64) (map[int64]struct{}, error) {
+	var sentIDs []int64
+	err := l.svcCtx.DB.Table(alertSendsTable).
+		Where("run_id = ? AND subscription_id IN ?", runID, ids).
+		Pluck("subscription_id", &sentIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	sent := make(map[int64]struct{}, len(sentIDs))
+	for _, id := range sentIDs {
+		sent[id] = struct{}{}
+	}
+	return sent, nil
+}
+
+// reserveSend 在同一事务中写入发送记录并扣减订阅次数, 已有发送记录或次数已用完时返回 false
+func (l *SendLowBatteryAlertLogic) reserveSend(runID, subscriptionID int64) (bool, error) {
+	err := l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(alertSendsTable).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&AlertSend{RunID: runID, SubscriptionID: subscriptionID, CreatedAt: time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSendSkipped
+		}
+		result = tx.Table(alertSubscriptionsTable).
+			Where("id = ? AND count > 0", subscriptionID).
+			Update("count", gorm.Expr("count - 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSendSkipped
+		}
+		return nil
+	})
+	if errors.Is(err, errSendSkipped) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// cancelSend 发送失败时删除发送记录并恢复订阅次数
+func (l *SendLowBatteryAlertLogic) cancelSend(runID, subscriptionID int64) error {
+	return l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(alertSendsTable).
+			Where("run_id = ? AND subscription_id = ?", runID, subscriptionID).
+			Delete(&AlertSend{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Table(alertSubscriptionsTable).
+			Where("id = ?", subscriptionID).
+			Update("count", gorm.Expr("count + 1")).Error
+	})
+}
@@ -0,0 +1,36 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertRunResumable(t *testing.T) {
+	now := time.Date(2025, 3, 1, 9, 30, 0, 0, time.Local)
+	run := AlertRun{Status: alertRunRunning, StartedAt: now.Add(-time.Hour)}
+	assert.True(t, run.resumable(now, 12*time.Hour))
+	assert.False(t, run.resumable(now, 30*time.Minute))
+
+	run.Status = alertRunFinished
+	assert.False(t, run.resumable(now, 12*time.Hour))
+}
+
+func TestLowBatteryAlertStatsOf(t *testing.T) {
+	stats := LowBatteryAlertStats{
+		AlertStats:  AlertStats{Total: 5, NeedSend: 2, SendSuccess: 2, NoSend: 1},
+		RunID:       42,
+		AlreadySent: 2,
+	}
+	assert.Equal(t, map[string]int{
+		"total":          5,
+		"process_failed": 0,
+		"need_send":      2,
+		"send_success":   2,
+		"send_failed":    0,
+		"no_send":        1,
+		"run_id":         42,
+		"already_sent":   2,
+	}, statsOf(stats))
+}
@@ -2,6 +2,7 @@ package cron
 
 import (
 	"context"
+	"yxy-go/internal/alert"
 	"yxy-go/internal/logic/bus"
 	"yxy-go/internal/svc"
 
@@ -9,50 +10,100 @@ import (
 )
 
 type CronJob struct {
-	ctx    context.Context
-	svcCtx *svc.ServiceContext
+	ctx       context.Context
+	svcCtx    *svc.ServiceContext
+	scheduler *Scheduler
 	logx.Logger
 }
 
 func NewCronJob(ctx context.Context, svcCtx *svc.ServiceContext) *CronJob {
 	return &CronJob{
-		ctx:    ctx,
-		svcCtx: svcCtx,
-		Logger: logx.WithContext(ctx),
+		ctx:       ctx,
+		svcCtx:    svcCtx,
+		scheduler: NewScheduler(ctx, svcCtx),
+		Logger:    logx.WithContext(ctx),
 	}
 }
 
 func (c *CronJob) MustRegister() {
-	_, err := c.svcCtx.Cron.AddFunc(c.svcCtx.Config.LowBattery.CronTime, func() {
-		l := NewSendLowBatteryAlertLogic(c.ctx, c.svcCtx)
-		l.Logger.Info("开始发送低电量提醒")
-		l.SendLowBatteryAlertLogic()
-		l.Logger.Info("结束发送低电量提醒")
+	c.scheduler.MustRegister(Job{
+		Name:     "low_battery_alert",
+		Schedule: c.svcCtx.Config.LowBattery.CronTime,
+		Enabled:  c.svcCtx.Config.LowBattery.EnableCron,
+		Run: func(ctx context.Context) (any, error) {
+			return NewSendLowBatteryAlertLogic(ctx, c.svcCtx).SendLowBatteryAlertLogic()
+		},
 	})
-	if err != nil {
-		panic(err)
-	}
-	c.Logger.Info("低电量提醒定时任务注册成功")
 
-	_, err = c.svcCtx.Cron.AddFunc(c.svcCtx.Config.BusService.BusInfoCronTime, func() {
-		l := bus.NewGetBusInfoLogic(c.ctx, c.svcCtx)
-		l.Logger.Info("开始获取校车信息")
-		l.UpdateBusInfo()
-		l.Logger.Info("结束获取校车信息")
+	c.scheduler.MustRegister(Job{
+		Name:     "low_card_balance_alert",
+		Schedule: c.svcCtx.Config.LowCardBalance.CronTime,
+		Enabled:  c.svcCtx.Config.LowCardBalance.EnableCron,
+		Run: func(ctx context.Context) (any, error) {
+			return NewSendLowCardBalanceAlertLogic(ctx, c.svcCtx).SendLowCardBalanceAlertLogic()
+		},
 	})
-	if err != nil {
-		panic(err)
-	}
-	c.Logger.Info("校车信息查询定时任务注册成功")
 
-	_, err = c.svcCtx.Cron.AddFunc(c.svcCtx.Config.BusService.BusAnnouncementCronTime, func() {
-		l := bus.NewGetBusAnnouncementLogic(c.ctx, c.svcCtx)
-		l.Logger.Info("开始获取校车公告信息")
-		l.UpdateAnnouncement()
-		l.Logger.Info("结束获取校车公告信息")
+	c.mustRegisterAlertRules()
+
+	c.scheduler.MustRegister(Job{
+		Name:     "bus_info",
+		Schedule: c.svcCtx.Config.BusService.BusInfoCronTime,
+		Enabled:  true,
+		Run: func(ctx context.Context) (any, error) {
+			l := bus.NewGetBusInfoLogic(ctx, c.svcCtx)
+			watchNotifier := alert.NewSeatWatchNotifier(ctx, c.svcCtx)
+			ruleNotifier := alert.NewSeatsOpenedRuleNotifier(ctx, c.svcCtx)
+			l.OnSeatsOpened(func(openings []bus.SeatOpening) {
+				watchNotifier.Notify(openings)
+				ruleNotifier.Notify(openings)
+			})
+			return nil, l.UpdateBusInfo()
+		},
 	})
-	c.Logger.Info("校车公告信息查询定时任务注册成功")
-	if err != nil {
-		panic(err)
+
+	c.scheduler.MustRegister(Job{
+		Name:     "bus_announcement",
+		Schedule: c.svcCtx.Config.BusService.BusAnnouncementCronTime,
+		Enabled:  true,
+		Run: func(ctx context.Context) (any, error) {
+			l := bus.NewGetBusAnnouncementLogic(ctx, c.svcCtx)
+			l.OnAnnouncementsChanged(alert.NewAnnouncementNotifier(ctx, c.svcCtx).Notify)
+			return nil, l.UpdateAnnouncement()
+		},
+	})
+}
+
+// Start 启动定时任务
+func (c *CronJob) Start() error {
+	return c.scheduler.Start()
+}
+
+// Stop 停止定时任务
+func (c *CronJob) Stop() {
+	c.scheduler.Stop()
+}
+
+// mustRegisterAlertRules 每个数据来源注册一个定时任务, 判断该来源下的全部提醒规则
+func (c *CronJob) mustRegisterAlertRules() {
+	sources := alert.NewDefaultEngine(c.ctx, c.svcCtx).Sources()
+	cronTimes := map[string]string{
+		alert.SourceCard:        c.svcCtx.Config.AlertRule.CardCronTime,
+		alert.SourceElectricity: c.svcCtx.Config.AlertRule.ElectricityCronTime,
+		alert.SourceBus:         c.svcCtx.Config.AlertRule.BusCronTime,
+	}
+	for _, source := range sources {
+		if cronTimes[source] == "" {
+			c.Logger.Infof("未配置提醒规则定时任务执行时间, 跳过: %s", source)
+			continue
+		}
+		c.scheduler.MustRegister(Job{
+			Name:     "alert_rule_" + source,
+			Schedule: cronTimes[source],
+			Enabled:  c.svcCtx.Config.AlertRule.EnableCron,
+			Run: func(ctx context.Context) (any, error) {
+				return alert.NewDefaultEngine(ctx, c.svcCtx).Run(source)
+			},
+		})
 	}
 }
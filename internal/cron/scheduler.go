@@ -0,0 +1,315 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+	jobManager "yxy-go/internal/manager/job"
+	"yxy-go/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/jsonx"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Job 定时任务的定义
+type Job struct {
+	Name     string
+	Schedule string
+	Enabled  bool          // 未开启的任务不按计划执行, 但仍可手动触发
+	Timeout  time.Duration // 为 0 时使用 Job.DefaultTimeout
+	Overlap  string        // 为空时为 OverlapSkip
+	Run      func(ctx context.Context) (stats any, err error)
+}
+
+type scheduledJob struct {
+	Job
+	running atomic.Int32
+}
+
+// Scheduler 定时任务注册表, 记录每次执行的结果, 并执行通过管理接口手动触发的任务;
+// 部署多个实例时只有主节点执行任务
+type Scheduler struct {
+	logx.Logger
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	manager       *jobManager.JobManager
+	triggers      *jobManager.JobManager
+	elector       *LeaderElector
+	jobs          map[string]*scheduledJob
+	names         []string
+	stop          chan struct{}
+	wg            sync.WaitGroup
+	cancelConsume context.CancelFunc
+	runCtx        context.Context
+	cancelRuns    context.CancelFunc
+	runs          sync.WaitGroup
+	mu            sync.Mutex
+	stopping      bool
+}
+
+// shutdownGrace 取消任务的 context 后, 等待任务保存执行记录的时长
+const shutdownGrace = time.Second * 5
+
+func NewScheduler(ctx context.Context, svcCtx *svc.ServiceContext) *Scheduler {
+	consumeCtx, cancelConsume := context.WithCancel(ctx)
+	runCtx, cancelRuns := context.WithCancel(ctx)
+	return &Scheduler{
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		manager:       jobManager.NewJobManager(ctx, svcCtx),
+		triggers:      jobManager.NewJobManager(consumeCtx, svcCtx),
+		elector:       NewLeaderElector(ctx, svcCtx),
+		jobs:          make(map[string]*scheduledJob),
+		stop:          make(chan struct{}),
+		cancelConsume: cancelConsume,
+		runCtx:        runCtx,
+		cancelRuns:    cancelRuns,
+	}
+}
+
+// MustRegister 注册定时任务, 开启的任务加入 cron 计划
+func (s *Scheduler) MustRegister(job Job) {
+	if _, ok := s.jobs[job.Name]; ok {
+		panic(fmt.Sprintf("duplicate cron job: %s", job.Name))
+	}
+	if job.Timeout == 0 {
+		job.Timeout = s.svcCtx.Config.Job.DefaultTimeout
+	}
+	if job.Overlap == "" {
+		job.Overlap = jobManager.OverlapSkip
+	}
+	j := &scheduledJob{Job: job}
+	if job.Enabled {
+		_, err := s.svcCtx.Cron.AddFunc(job.Schedule, func() {
+			s.run(j, jobManager.TriggerSchedule)
+		})
+		if err != nil {
+			panic(err)
+		}
+		s.Logger.Infof("定时任务注册成功: %s", job.Name)
+	}
+	s.jobs[job.Name] = j
+	s.names = append(s.names, job.Name)
+}
+
+// Start 保存任务注册信息, 启动 cron 和手动触发队列的消费
+func (s *Scheduler) Start() error {
+	infos := make([]jobManager.JobInfo, 0, len(s.names))
+	for _, name := range s.names {
+		j := s.jobs[name]
+		infos = append(infos, jobManager.JobInfo{
+			Name:     j.Name,
+			Schedule: j.Schedule,
+			Enabled:  j.Enabled,
+			Timeout:  j.Timeout,
+			Overlap:  j.Overlap,
+		})
+	}
+	if err := s.manager.SaveJobs(infos); err != nil {
+		return err
+	}
+	// 新的主节点清除上一个主节点遗留的执行中记录
+	s.elector.OnElected(func() {
+		if err := s.manager.ClearRunning(); err != nil {
+			s.Logger.Errorf("清除定时任务执行中记录失败: %v", err)
+		}
+	})
+	s.elector.Start()
+
+	s.wg.Add(1)
+	go s.consumeTriggers()
+	s.svcCtx.Cron.Start()
+	return nil
+}
+
+// Stop 停止调度和手动触发队列的消费, 等待执行中的任务结束;
+// 超过 Job.ShutdownTimeout 时取消任务的 context, 任务保存执行记录后退出
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	s.cancelConsume()
+	s.svcCtx.Cron.Stop()
+	s.wg.Wait()
+
+	if !waitTimeout(&s.runs, s.svcCtx.Config.Job.ShutdownTimeout) {
+		s.Logger.Errorf("等待定时任务结束超时, 取消执行中的任务")
+		s.cancelRuns()
+		if !waitTimeout(&s.runs, shutdownGrace) {
+			s.Logger.Errorf("定时任务取消后仍未结束, 强制退出")
+		}
+	}
+	s.elector.Stop()
+}
+
+// waitTimeout 等待 WaitGroup 结束, 超时返回 false
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// consumeTriggers 主节点从队列中取出手动触发的任务并执行
+func (s *Scheduler) consumeTriggers() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+		if !s.elector.IsLeader() {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(time.Second * 5):
+			}
+			continue
+		}
+		name, err := s.triggers.PopTrigger(time.Second * 5)
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			s.Logger.Errorf("获取手动触发的定时任务失败: %v", err)
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(time.Second * 5):
+			}
+			continue
+		}
+		j, ok := s.jobs[name]
+		if !ok {
+			s.Logger.Errorf("手动触发的定时任务不存在: %s", name)
+			continue
+		}
+		go s.run(j, jobManager.TriggerManual)
+	}
+}
+
+// run 执行一次任务并保存执行记录
+func (s *Scheduler) run(j *scheduledJob, trigger string) {
+	if trigger == jobManager.TriggerSchedule && !s.elector.IsLeader() {
+		return
+	}
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return
+	}
+	s.runs.Add(1)
+	s.mu.Unlock()
+	defer s.runs.Done()
+
+	record := jobManager.JobRun{
+		Name:      j.Name,
+		Trigger:   trigger,
+		Status:    jobManager.StatusRunning,
+		StartedAt: time.Now().UnixMilli(),
+	}
+	if j.Overlap == jobManager.OverlapSkip && !j.running.CompareAndSwap(0, 1) {
+		s.Logger.Infof("定时任务上一次执行尚未结束, 跳过: %s", j.Name)
+		record.Status = jobManager.StatusSkipped
+		record.EndedAt = record.StartedAt
+		s.finish(record)
+		return
+	}
+	if j.Overlap != jobManager.OverlapSkip {
+		j.running.Add(1)
+	}
+	defer j.running.Add(-1)
+
+	if err := s.manager.StartRun(record); err != nil {
+		s.Logger.Errorf("保存定时任务执行记录失败: %v", err)
+	}
+	s.Logger.Infof("开始执行定时任务: %s (%s)", j.Name, trigger)
+
+	ctx, cancel := context.WithTimeout(s.runCtx, j.Timeout)
+	defer cancel()
+	// 失去主节点时取消任务, 避免与新的主节点重复执行
+	stopTerm := context.AfterFunc(s.elector.Term(), cancel)
+	defer stopTerm()
+	stats, err := s.call(ctx, j)
+
+	record.EndedAt = time.Now().UnixMilli()
+	record.Status = runStatus(ctx, err)
+	record.Stats = statsOf(stats)
+	if err != nil {
+		record.Error = err.Error()
+	}
+	s.finish(record)
+	s.Logger.Infof("结束执行定时任务: %s, 状态: %s, 耗时: %dms", j.Name, record.Status, record.EndedAt-record.StartedAt)
+}
+
+// call 执行任务, 任务 panic 时视为执行失败
+func (s *Scheduler) call(ctx context.Context, j *scheduledJob) (stats any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return j.Run(ctx)
+}
+
+func (s *Scheduler) finish(record jobManager.JobRun) {
+	if err := s.manager.FinishRun(record); err != nil {
+		s.Logger.Errorf("保存定时任务执行记录失败: %v", err)
+	}
+}
+
+// runStatus 根据任务返回的错误和 context 判断执行状态
+func runStatus(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return jobManager.StatusTimeout
+	case errors.Is(ctx.Err(), context.Canceled):
+		return jobManager.StatusCanceled
+	case err != nil:
+		return jobManager.StatusFailed
+	default:
+		return jobManager.StatusSuccess
+	}
+}
+
+// statsOf 将任务返回的统计结构体转为 map, 只保留整数字段
+func statsOf(stats any) map[string]int {
+	if stats == nil {
+		return nil
+	}
+	data, err := jsonx.Marshal(stats)
+	if err != nil {
+		return nil
+	}
+	var raw map[string]any
+	if err = jsonx.Unmarshal(data, &raw); err != nil {
+		return nil
+	}
+	result := make(map[string]int, len(raw))
+	for k, v := range raw {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := n.Int64(); err == nil {
+			result[k] = int(i)
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	jobManager "yxy-go/internal/manager/job"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStatus(t *testing.T) {
+	assert.Equal(t, jobManager.StatusSuccess, runStatus(context.Background(), nil))
+	assert.Equal(t, jobManager.StatusFailed, runStatus(context.Background(), errors.New("query failed")))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	assert.Equal(t, jobManager.StatusTimeout, runStatus(ctx, ctx.Err()))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, jobManager.StatusCanceled, runStatus(ctx, ctx.Err()))
+}
+
+func TestWaitTimeout(t *testing.T) {
+	var wg sync.WaitGroup
+	assert.True(t, waitTimeout(&wg, time.Millisecond))
+
+	wg.Add(1)
+	assert.False(t, waitTimeout(&wg, time.Millisecond*10))
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		wg.Done()
+	}()
+	assert.True(t, waitTimeout(&wg, time.Second))
+}
+
+func TestStatsOf(t *testing.T) {
+	assert.Nil(t, statsOf(nil))
+	assert.Equal(t, map[string]int{
+		"total":          3,
+		"process_failed": 1,
+		"need_send":      2,
+		"send_success":   1,
+		"send_failed":    1,
+		"no_send":        0,
+	}, statsOf(AlertStats{Total: 3, ProcessFailed: 1, NeedSend: 2, SendSuccess: 1, SendFailed: 1}))
+}
@@ -13,7 +13,6 @@ import (
 	"github.com/ArtisanCloud/PowerWeChat/v3/src/basicService/subscribeMessage/request"
 	"github.com/ArtisanCloud/PowerWeChat/v3/src/kernel/power"
 	"github.com/zeromicro/go-zero/core/logx"
-	"gorm.io/gorm"
 )
 
 type SendLowBatteryAlertLogic struct {
@@ -40,70 +39,141 @@ type Subscription struct {
 	Count     int64  `gorm:"column:count"`
 }
 
-// SendLowBatteryAlertLogic 发送低电量提醒
-func (l *SendLowBatteryAlertLogic) SendLowBatteryAlertLogic() {
-	stats := struct {
-		Total         int // 总条数
-		ProcessFailed int // 处理过程中出错的条数（如获取电量失败）
-		NeedSend      int // 需要发送的条数（电量低于阈值）
-		SendSuccess   int // 发送成功的条数
-		SendFailed    int // 发送失败的条数
-		NoSend        int // 无需发送的条数（电量高于阈值）
-	}{}
-
-	page := 1
+// AlertStats 提醒发送统计
+type AlertStats struct {
+	Total         int `json:"total"`          // 总条数
+	ProcessFailed int `json:"process_failed"` // 处理过程中出错的条数（如获取电量、余额失败）
+	NeedSend      int `json:"need_send"`      // 需要发送的条数（低于阈值）
+	SendSuccess   int `json:"send_success"`   // 发送成功的条数
+	SendFailed    int `json:"send_failed"`    // 发送失败的条数
+	NoSend        int `json:"no_send"`        // 无需发送的条数（高于阈值）
+}
+
+// LowBatteryAlertStats 低电量提醒发送统计
+type LowBatteryAlertStats struct {
+	AlertStats
+	RunID       int64 `json:"run_id"`       // 运行 ID, 中途退出后重试时沿用
+	AlreadySent int   `json:"already_sent"` // 本次运行中已发送过而跳过的条数
+}
+
+type sendResult int
+
+const (
+	resultNoSend sendResult = iota
+	resultSent
+	resultAlreadySent
+)
+
+// SendLowBatteryAlertLogic 发送低电量提醒, 按订阅 ID 顺序处理并记录进度, 中途退出时下次从断点继续且不会重复发送
+func (l *SendLowBatteryAlertLogic) SendLowBatteryAlertLogic() (LowBatteryAlertStats, error) {
+	var stats LowBatteryAlertStats
+	run, err := l.startRun()
+	if err != nil {
+		l.Logger.Errorf("Start low battery alert run failed: %v", err)
+		return stats, err
+	}
+	stats.RunID = run.ID
+
 	pageSize := 100
+	// 游标只越过已处理完成的订阅, 处理出错的订阅在继续执行时重新处理
+	lastID, blocked := run.CursorID, false
 	for {
-		subscriptions, err := l.querySubscriptionByPage(page, pageSize)
+		subscriptions, err := l.querySubscriptionsAfter(lastID, pageSize)
 		if err != nil {
 			l.Logger.Errorf("Query subscriptions failed: %v", err)
-			return
+			return stats, err
 		}
 		if len(subscriptions) == 0 {
 			break
 		}
 		stats.Total += len(subscriptions)
+		lastID = subscriptions[len(subscriptions)-1].ID
+
+		ids := make([]int64, len(subscriptions))
+		for i, subscription := range subscriptions {
+			ids[i] = subscription.ID
+		}
+		sent, err := l.sentSubscriptions(run.ID, ids)
+		if err != nil {
+			l.Logger.Errorf("Query send records of run %d failed: %v", run.ID, err)
+			return stats, err
+		}
 
-		var sendIDs []int64
 		for _, subscription := range subscriptions {
-			needSend, err := l.processSubscription(subscription)
-			if err != nil {
+			// 定时服务退出时停止发送, 下次从已处理的位置继续
+			if l.ctx.Err() != nil {
+				break
+			}
+			if _, ok := sent[subscription.ID]; ok {
+				stats.AlreadySent++
+			} else if result, err := l.processSubscription(run.ID, subscription); err != nil {
 				if errors.Is(err, ErrSendFailed) {
 					stats.NeedSend++
 					stats.SendFailed++
 					l.Logger.Errorf("Send alert to user ID %d (OpenID: %s) failed: %v", subscription.UserID, subscription.OpenID, err)
 				} else {
 					stats.ProcessFailed++
+					blocked = true
 					l.Logger.Errorf("Process subscription for user ID %d (OpenID: %s) failed: %v", subscription.UserID, subscription.OpenID, err)
 				}
-				continue
-			}
-			if needSend {
-				sendIDs = append(sendIDs, subscription.ID)
-				stats.NeedSend++
-				stats.SendSuccess++
 			} else {
-				stats.NoSend++
+				switch result {
+				case resultSent:
+					stats.NeedSend++
+					stats.SendSuccess++
+				case resultAlreadySent:
+					stats.AlreadySent++
+				default:
+					stats.NoSend++
+				}
+			}
+			// 处理中途被取消的订阅视为未完成
+			if l.ctx.Err() != nil {
+				blocked = true
 			}
+			if !blocked {
+				run.CursorID = subscription.ID
+			}
+		}
+		if err := l.saveCursor(run); err != nil {
+			l.Logger.Errorf("Save cursor of run %d failed: %v", run.ID, err)
 		}
-		if err := l.decrementSubscriptionCount(sendIDs); err != nil {
-			l.Logger.Errorf("Decrement subscription count failed for user IDs: %v, error: %v", sendIDs, err)
+		if err := l.ctx.Err(); err != nil {
+			l.Logger.Errorf("Low battery alert run %d canceled: %v", run.ID, err)
+			l.logStats(stats)
+			return stats, err
 		}
-		page++
 	}
-	l.Logger.Infof("Low battery alert statistics: Total=%d, ProcessFailed=%d, NeedSend=%d, SentSuccess=%d, SentFailed=%d, NoSend=%d",
-		stats.Total, stats.ProcessFailed, stats.NeedSend, stats.SendSuccess, stats.SendFailed, stats.NoSend)
+	if err := l.finishRun(run); err != nil {
+		l.Logger.Errorf("Finish low battery alert run %d failed: %v", run.ID, err)
+	}
+	l.logStats(stats)
+	return stats, nil
+}
+
+func (l *SendLowBatteryAlertLogic) logStats(stats LowBatteryAlertStats) {
+	l.Logger.Infof("Low battery alert statistics (run %d): Total=%d, ProcessFailed=%d, NeedSend=%d, SentSuccess=%d, SentFailed=%d, NoSend=%d, AlreadySent=%d",
+		stats.RunID, stats.Total, stats.ProcessFailed, stats.NeedSend, stats.SendSuccess, stats.SendFailed, stats.NoSend, stats.AlreadySent)
 }
 
 var ErrSendFailed = errors.New("send alert failed")
 
-func (l *SendLowBatteryAlertLogic) processSubscription(subscription Subscription) (bool, error) {
+// processSubscription 电量低于阈值时发送提醒; 发送前在事务中写入发送记录并扣减次数, 发送失败时撤销,
+// 进程在写入记录后、发送前退出时本次最多漏发, 重试时不会重复发送
+func (l *SendLowBatteryAlertLogic) processSubscription(runID int64, subscription Subscription) (sendResult, error) {
 	resp, err := l.getElecSurplus(subscription.YxyUID, subscription.Campus)
 	if err != nil {
-		return false, fmt.Errorf("get electricity surplus failed: %w", err)
+		return resultNoSend, fmt.Errorf("get electricity surplus failed: %w", err)
 	}
 	if resp.Surplus > float64(subscription.Threshold) {
-		return false, nil
+		return resultNoSend, nil
+	}
+	reserved, err := l.reserveSend(runID, subscription.ID)
+	if err != nil {
+		return resultNoSend, fmt.Errorf("reserve send record failed: %w", err)
+	}
+	if !reserved {
+		return resultAlreadySent, nil
 	}
 	if len([]rune(resp.DisplayRoomName)) > 20 {
 		resp.DisplayRoomName = strings.Replace(resp.DisplayRoomName, "校区", "", 1)
@@ -126,30 +196,39 @@ func (l *SendLowBatteryAlertLogic) processSubscription(subscription Subscription
 			},
 		},
 	})
-	if err != nil {
-		return true, fmt.Errorf("%w: %v", ErrSendFailed, err)
-	}
-	if mpResp.ErrCode != 0 {
+	if err == nil && mpResp.ErrCode != 0 {
 		// errCode: 43101, errMsg: user refuse to accept the msg
 		if mpResp.ErrCode == 43101 {
+			l.cancelSendOrLog(runID, subscription.ID)
 			_ = l.resetSubscriptionCount(subscription.ID)
-			return false, nil
+			return resultNoSend, nil
 		}
-		return true, fmt.Errorf("%w: errcode: %d, errmsg: %s", ErrSendFailed, mpResp.ErrCode, mpResp.ErrMsg)
+		err = fmt.Errorf("errcode: %d, errmsg: %s", mpResp.ErrCode, mpResp.ErrMsg)
+	}
+	if err != nil {
+		l.cancelSendOrLog(runID, subscription.ID)
+		return resultNoSend, fmt.Errorf("%w: %v", ErrSendFailed, err)
 	}
 	l.Logger.Infof("Send alert to user ID %d (OpenID: %s) successfully, electricity surplus: %.2f, threshold: %d",
 		subscription.UserID, subscription.OpenID, resp.Surplus, subscription.Threshold)
-	return true, nil
+	return resultSent, nil
+}
+
+func (l *SendLowBatteryAlertLogic) cancelSendOrLog(runID, subscriptionID int64) {
+	if err := l.cancelSend(runID, subscriptionID); err != nil {
+		l.Logger.Errorf("Cancel send record of subscription %d in run %d failed: %v", subscriptionID, runID, err)
+	}
 }
 
-func (l *SendLowBatteryAlertLogic) querySubscriptionByPage(page, pageSize int) ([]Subscription, error) {
+// querySubscriptionsAfter 按订阅 ID 分页查询, 运行中订阅次数变为 0 时不影响后续分页
+func (l *SendLowBatteryAlertLogic) querySubscriptionsAfter(cursorID int64, pageSize int) ([]Subscription, error) {
 	var subscriptions []Subscription
-	err := l.svcCtx.DB.Table("low_battery_alert_subscriptions lbas").
+	err := l.svcCtx.DB.Table(alertSubscriptionsTable+" lbas").
 		Select("lbas.id, lbas.user_id, lbas.campus, lbas.threshold, lbas.count, u.wechat_open_id as openid, u.yxy_uid as yxy_uid").
 		Joins("JOIN users u ON lbas.user_id = u.id").
-		Where("lbas.count > 0").
+		Where("lbas.count > 0 AND lbas.id > ?", cursorID).
+		Order("lbas.id").
 		Limit(pageSize).
-		Offset((page - 1) * pageSize).
 		Find(&subscriptions).Error
 	if err != nil {
 		return nil, err
@@ -157,18 +236,8 @@ func (l *SendLowBatteryAlertLogic) querySubscriptionByPage(page, pageSize int) (
 	return subscriptions, nil
 }
 
-func (l *SendLowBatteryAlertLogic) decrementSubscriptionCount(ids []int64) error {
-	if len(ids) == 0 {
-		return nil
-	}
-	err := l.svcCtx.DB.Table("low_battery_alert_subscriptions").
-		Where("id IN ?", ids).
-		Update("count", gorm.Expr("count - 1")).Error
-	return err
-}
-
 func (l *SendLowBatteryAlertLogic) resetSubscriptionCount(id int64) error {
-	err := l.svcCtx.DB.Table("low_battery_alert_subscriptions").
+	err := l.svcCtx.DB.Table(alertSubscriptionsTable).
 		Where("id = ?", id).
 		Update("count", 0).Error
 	return err
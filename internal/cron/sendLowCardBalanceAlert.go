@@ -0,0 +1,203 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"yxy-go/internal/logic/card"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/ArtisanCloud/PowerWeChat/v3/src/basicService/subscribeMessage/request"
+	"github.com/ArtisanCloud/PowerWeChat/v3/src/kernel/power"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type SendLowCardBalanceAlertLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSendLowCardBalanceAlertLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SendLowCardBalanceAlertLogic {
+	return &SendLowCardBalanceAlertLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CardBalanceSubscription 校园卡余额不足提醒订阅, 通过管理接口创建和更新, 表结构:
+//
+//	CREATE TABLE low_card_balance_alert_subscriptions (
+//	    id        BIGINT PRIMARY KEY AUTO_INCREMENT,
+//	    user_id   BIGINT        NOT NULL,
+//	    device_id VARCHAR(64)   NOT NULL DEFAULT '',
+//	    threshold DECIMAL(10,2) NOT NULL,
+//	    count     INT           NOT NULL DEFAULT 0, -- 剩余提醒次数
+//	    UNIQUE KEY uk_user (user_id)
+//	);
+type CardBalanceSubscription struct {
+	ID        int64   `gorm:"column:id"`
+	UserID    int64   `gorm:"column:user_id"`
+	OpenID    string  `gorm:"column:openid"`
+	YxyUID    string  `gorm:"column:yxy_uid"`
+	DeviceID  string  `gorm:"column:device_id"`
+	Threshold float64 `gorm:"column:threshold"`
+	Count     int64   `gorm:"column:count"`
+}
+
+// SendLowCardBalanceAlertLogic 发送校园卡余额不足提醒
+func (l *SendLowCardBalanceAlertLogic) SendLowCardBalanceAlertLogic() (AlertStats, error) {
+	var stats AlertStats
+
+	// 按订阅 ID 分页, 发送后扣减次数不会影响后续分页
+	var lastID int64
+	pageSize := 100
+	for {
+		subscriptions, err := l.querySubscriptionsAfter(lastID, pageSize)
+		if err != nil {
+			l.Logger.Errorf("Query subscriptions failed: %v", err)
+			return stats, err
+		}
+		if len(subscriptions) == 0 {
+			break
+		}
+		lastID = subscriptions[len(subscriptions)-1].ID
+		stats.Total += len(subscriptions)
+
+		var sendIDs []int64
+		for _, subscription := range subscriptions {
+			// 定时服务退出时停止发送, 已发送的订阅仍需扣减次数
+			if l.ctx.Err() != nil {
+				break
+			}
+			needSend, err := l.processSubscription(subscription)
+			if err != nil {
+				if errors.Is(err, ErrSendFailed) {
+					stats.NeedSend++
+					stats.SendFailed++
+					l.Logger.Errorf("Send alert to user ID %d (OpenID: %s) failed: %v", subscription.UserID, subscription.OpenID, err)
+				} else {
+					stats.ProcessFailed++
+					l.Logger.Errorf("Process subscription for user ID %d (OpenID: %s) failed: %v", subscription.UserID, subscription.OpenID, err)
+				}
+				continue
+			}
+			if needSend {
+				sendIDs = append(sendIDs, subscription.ID)
+				stats.NeedSend++
+				stats.SendSuccess++
+			} else {
+				stats.NoSend++
+			}
+		}
+		if err := l.decrementSubscriptionCount(sendIDs); err != nil {
+			l.Logger.Errorf("Decrement subscription count failed for user IDs: %v, error: %v", sendIDs, err)
+		}
+		if err := l.ctx.Err(); err != nil {
+			l.Logger.Errorf("Low card balance alert canceled: %v", err)
+			l.logStats(stats)
+			return stats, err
+		}
+	}
+	l.logStats(stats)
+	return stats, nil
+}
+
+func (l *SendLowCardBalanceAlertLogic) logStats(stats AlertStats) {
+	l.Logger.Infof("Low card balance alert statistics: Total=%d, ProcessFailed=%d, NeedSend=%d, SentSuccess=%d, SentFailed=%d, NoSend=%d",
+		stats.Total, stats.ProcessFailed, stats.NeedSend, stats.SendSuccess, stats.SendFailed, stats.NoSend)
+}
+
+func (l *SendLowCardBalanceAlertLogic) processSubscription(subscription CardBalanceSubscription) (bool, error) {
+	balance, err := l.getCardBalance(subscription.YxyUID, subscription.DeviceID)
+	if err != nil {
+		return false, fmt.Errorf("get card balance failed: %w", err)
+	}
+	// 余额低于阈值时才提醒, 与提醒内容 "余额低于" 一致
+	if balance >= subscription.Threshold {
+		return false, nil
+	}
+	mpResp, err := l.svcCtx.MiniProgram.SubscribeMessage.Send(l.ctx, &request.RequestSubscribeMessageSend{
+		ToUser:           subscription.OpenID,
+		TemplateID:       l.svcCtx.Config.LowCardBalance.TemplateID,
+		Page:             "/pages/school-card/index",
+		MiniProgramState: l.svcCtx.Config.LowCardBalance.MiniProgramState,
+		Lang:             "zh_CN",
+		Data: &power.HashMap{
+			"amount1": power.StringMap{ // 卡内余额
+				"value": strconv.FormatFloat(balance, 'f', 2, 64),
+			},
+			"thing2": power.StringMap{ // 备注
+				"value": "校园卡余额低于 " + strconv.FormatFloat(subscription.Threshold, 'f', -1, 64) + " 元，请及时充值",
+			},
+		},
+	})
+	if err != nil {
+		return true, fmt.Errorf("%w: %v", ErrSendFailed, err)
+	}
+	if mpResp.ErrCode != 0 {
+		// errCode: 43101, errMsg: user refuse to accept the msg
+		if mpResp.ErrCode == 43101 {
+			_ = l.resetSubscriptionCount(subscription.ID)
+			return false, nil
+		}
+		return true, fmt.Errorf("%w: errcode: %d, errmsg: %s", ErrSendFailed, mpResp.ErrCode, mpResp.ErrMsg)
+	}
+	l.Logger.Infof("Send alert to user ID %d (OpenID: %s) successfully, card balance: %.2f, threshold: %.2f",
+		subscription.UserID, subscription.OpenID, balance, subscription.Threshold)
+	return true, nil
+}
+
+// querySubscriptionsAfter 按 ID 顺序获取 lastID 之后仍有剩余次数的订阅
+func (l *SendLowCardBalanceAlertLogic) querySubscriptionsAfter(lastID int64, pageSize int) ([]CardBalanceSubscription, error) {
+	var subscriptions []CardBalanceSubscription
+	err := l.svcCtx.DB.Table("low_card_balance_alert_subscriptions lcbas").
+		Select("lcbas.id, lcbas.user_id, lcbas.device_id, lcbas.threshold, lcbas.count, u.wechat_open_id as openid, u.yxy_uid as yxy_uid").
+		Joins("JOIN users u ON lcbas.user_id = u.id").
+		Where("lcbas.count > 0 AND lcbas.id > ?", lastID).
+		Order("lcbas.id").
+		Limit(pageSize).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (l *SendLowCardBalanceAlertLogic) decrementSubscriptionCount(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	err := l.svcCtx.DB.Table("low_card_balance_alert_subscriptions").
+		Where("id IN ?", ids).
+		Update("count", gorm.Expr("count - 1")).Error
+	return err
+}
+
+func (l *SendLowCardBalanceAlertLogic) resetSubscriptionCount(id int64) error {
+	err := l.svcCtx.DB.Table("low_card_balance_alert_subscriptions").
+		Where("id = ?", id).
+		Update("count", 0).Error
+	return err
+}
+
+// getCardBalance 获取主钱包余额, 设备信息和登录状态使用服务端为该用户保存的数据
+func (l *SendLowCardBalanceAlertLogic) getCardBalance(yxyUID, deviceID string) (float64, error) {
+	balanceLogic := card.NewGetCardBalanceLogic(l.ctx, l.svcCtx)
+	resp, err := balanceLogic.GetCardBalance(&types.GetCardBalanceReq{
+		UID:      yxyUID,
+		DeviceID: deviceID,
+		WalletNo: "1",
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Wallets) == 0 {
+		return 0, errors.New("wallet not found")
+	}
+	return strconv.ParseFloat(resp.Wallets[0].Balance, 64)
+}
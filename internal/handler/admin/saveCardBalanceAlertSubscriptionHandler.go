@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/admin"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func SaveCardBalanceAlertSubscriptionHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.SaveCardBalanceAlertSubscriptionReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := admin.NewSaveCardBalanceAlertSubscriptionLogic(r.Context(), svcCtx)
+		resp, err := l.SaveCardBalanceAlertSubscription(&req)
+		response.HttpResponse(r, w, resp, err)
+	}
+}
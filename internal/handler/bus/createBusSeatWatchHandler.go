@@ -0,0 +1,25 @@
+package bus
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func CreateBusSeatWatchHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CreateBusSeatWatchReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := bus.NewCreateBusSeatWatchLogic(r.Context(), svcCtx)
+		resp, err := l.CreateBusSeatWatch(&req)
+		response.HttpResponse(r, w, resp, err)
+	}
+}
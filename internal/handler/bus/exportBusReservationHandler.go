@@ -0,0 +1,29 @@
+package bus
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func ExportBusReservationHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ExportBusReservationReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := bus.NewExportBusReservationLogic(r.Context(), svcCtx)
+		file, err := l.ExportBusReservation(&req)
+		if err != nil {
+			response.HttpResponse(r, w, nil, err)
+			return
+		}
+		response.FileResponse(r, w, file.Name, file.ContentType, file.Write)
+	}
+}
@@ -0,0 +1,25 @@
+package bus
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func GetBusAnnouncementUpdatesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetBusAnnouncementUpdatesReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := bus.NewGetBusAnnouncementUpdatesLogic(r.Context(), svcCtx)
+		resp, err := l.GetBusAnnouncementUpdates(&req)
+		response.HttpResponse(r, w, resp, err)
+	}
+}
@@ -0,0 +1,29 @@
+package card
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/card"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func ExportCardConsumptionRecordsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ExportCardConsumptionRecordsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := card.NewExportCardConsumptionRecordsLogic(r.Context(), svcCtx)
+		file, err := l.ExportCardConsumptionRecords(&req)
+		if err != nil {
+			response.HttpResponse(r, w, nil, err)
+			return
+		}
+		response.FileResponse(r, w, file.Name, file.ContentType, file.Write)
+	}
+}
@@ -0,0 +1,25 @@
+package card
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/card"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func GetCardAnalyticsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetCardAnalyticsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := card.NewGetCardAnalyticsLogic(r.Context(), svcCtx)
+		resp, err := l.GetCardAnalytics(&req)
+		response.HttpResponse(r, w, resp, err)
+	}
+}
@@ -0,0 +1,25 @@
+package device
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"yxy-go/internal/logic/device"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/response"
+)
+
+func GetDeviceProfileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetDeviceProfileReq
+		if err := httpx.Parse(r, &req); err != nil {
+			response.ParamErrorResponse(r, w, err)
+			return
+		}
+
+		l := device.NewGetDeviceProfileLogic(r.Context(), svcCtx)
+		resp, err := l.GetDeviceProfile(&req)
+		response.HttpResponse(r, w, resp, err)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"time"
 
+	admin "yxy-go/internal/handler/admin"
 	bus "yxy-go/internal/handler/bus"
 	card "yxy-go/internal/handler/card"
+	device "yxy-go/internal/handler/device"
 	electricity "yxy-go/internal/handler/electricity"
 	login "yxy-go/internal/handler/login"
 	"yxy-go/internal/svc"
@@ -17,6 +19,55 @@ import (
 )
 
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.AdminAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/alert-rules",
+					Handler: admin.GetAlertRulesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/alert-rules",
+					Handler: admin.CreateAlertRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/alert-rules/:id",
+					Handler: admin.DeleteAlertRuleHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/bus/uid-pool",
+					Handler: admin.GetBusUIDPoolHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/card/balance-alert/subscriptions",
+					Handler: admin.SaveCardBalanceAlertSubscriptionHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/jobs",
+					Handler: admin.GetCronJobsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/jobs/:name/runs",
+					Handler: admin.GetCronJobRunsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/jobs/:name/trigger",
+					Handler: admin.TriggerCronJobHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/v1/admin"),
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -24,6 +75,21 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/announcement",
 				Handler: bus.GetBusAnnouncementHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/announcement/subscription",
+				Handler: bus.SubscribeBusAnnouncementHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/announcement/subscription",
+				Handler: bus.UnsubscribeBusAnnouncementHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/announcement/updates",
+				Handler: bus.GetBusAnnouncementUpdatesHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/info",
@@ -39,12 +105,47 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/reservation",
 				Handler: bus.GetBusReservationHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/reservation/export",
+				Handler: bus.ExportBusReservationHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/seat-history",
+				Handler: bus.GetBusSeatHistoryHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/timetable",
+				Handler: bus.GetBusTimetableHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/watches",
+				Handler: bus.CreateBusSeatWatchHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/watches",
+				Handler: bus.GetBusSeatWatchesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/watches/:id",
+				Handler: bus.DeleteBusSeatWatchHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1/bus"),
 	)
 
 	server.AddRoutes(
 		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/analytics",
+				Handler: card.GetCardAnalyticsHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/balance",
@@ -55,8 +156,42 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/consumption-records",
 				Handler: card.GetCardConsumptionRecordsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/consumption-records/export",
+				Handler: card.ExportCardConsumptionRecordsHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1/card"),
+		rest.WithTimeout(20000*time.Millisecond),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/consumption-records",
+				Handler: card.GetCardTransactionsHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v2/card"),
+		rest.WithTimeout(20000*time.Millisecond),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/profile",
+				Handler: device.GetDeviceProfileHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/profile/reset",
+				Handler: device.ResetDeviceProfileHandler(serverCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/device"),
 	)
 
 	server.AddRoutes(
@@ -66,6 +201,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/recharge-records",
 				Handler: electricity.GetElectricityRechargeRecordsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/recharge-records/export",
+				Handler: electricity.ExportElectricityRechargeRecordsHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/surplus",
@@ -76,6 +216,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/usage-records",
 				Handler: electricity.GetElectricityUsageRecordsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/usage-records/export",
+				Handler: electricity.ExportElectricityUsageRecordsHandler(serverCtx),
+			},
 		},
 		rest.WithPrefix("/api/v1/electricity"),
 		rest.WithTimeout(20000*time.Millisecond),
@@ -0,0 +1,93 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"yxy-go/internal/alert"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const alertRulesTable = "alert_rules"
+
+type CreateAlertRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCreateAlertRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateAlertRuleLogic {
+	return &CreateAlertRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+type alertRule struct {
+	ID        int64     `gorm:"column:id"`
+	UserID    int64     `gorm:"column:user_id"`
+	Kind      string    `gorm:"column:kind"`
+	Params    string    `gorm:"column:params"`
+	Cooldown  int64     `gorm:"column:cooldown"`
+	Enabled   bool      `gorm:"column:enabled"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+// CreateAlertRule 为用户创建提醒规则, 创建前已发布的校车公告不会触发关键字提醒
+func (l *CreateAlertRuleLogic) CreateAlertRule(req *types.CreateAlertRuleReq) (resp *types.CreateAlertRuleResp, err error) {
+	if !alert.ValidKind(req.Kind) {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("unknown kind %q", req.Kind))
+	}
+	if req.Cooldown < 0 {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("invalid cooldown %v", req.Cooldown))
+	}
+	if req.Params != "" {
+		var params map[string]any
+		if err = json.Unmarshal([]byte(req.Params), &params); err != nil {
+			return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("params must be a JSON object: %v", err))
+		}
+	}
+	var users int64
+	if err = l.svcCtx.DB.WithContext(l.ctx).Table("users").Where("id = ?", req.UserID).Count(&users).Error; err != nil {
+		return nil, err
+	}
+	if users == 0 {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("user %v not found", req.UserID))
+	}
+
+	rule := alertRule{
+		UserID:    req.UserID,
+		Kind:      req.Kind,
+		Params:    req.Params,
+		Cooldown:  req.Cooldown,
+		Enabled:   true,
+		CreatedAt: time.Now(),
+	}
+	if err = l.svcCtx.DB.WithContext(l.ctx).Table(alertRulesTable).Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	l.Logger.Infof("创建提醒规则: id=%d, user_id=%d, kind=%s", rule.ID, rule.UserID, rule.Kind)
+
+	return &types.CreateAlertRuleResp{
+		Rule: toAlertRule(rule),
+	}, nil
+}
+
+func toAlertRule(rule alertRule) types.AlertRule {
+	return types.AlertRule{
+		ID:        rule.ID,
+		UserID:    rule.UserID,
+		Kind:      rule.Kind,
+		Params:    rule.Params,
+		Cooldown:  rule.Cooldown,
+		Enabled:   rule.Enabled,
+		CreatedAt: rule.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,41 @@
+package admin
+
+import (
+	"context"
+	"strconv"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteAlertRuleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteAlertRuleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteAlertRuleLogic {
+	return &DeleteAlertRuleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteAlertRule 删除提醒规则, 冷却记录到期后自动清除
+func (l *DeleteAlertRuleLogic) DeleteAlertRule(req *types.DeleteAlertRuleReq) (resp *types.DeleteAlertRuleResp, err error) {
+	result := l.svcCtx.DB.WithContext(l.ctx).Table(alertRulesTable).
+		Where("id = ?", req.ID).
+		Delete(&alertRule{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, xerr.WithCode(xerr.ErrAlertRuleNotFound, strconv.FormatInt(req.ID, 10))
+	}
+	l.Logger.Infof("删除提醒规则: id=%d", req.ID)
+	return &types.DeleteAlertRuleResp{}, nil
+}
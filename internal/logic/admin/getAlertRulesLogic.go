@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetAlertRulesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetAlertRulesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetAlertRulesLogic {
+	return &GetAlertRulesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetAlertRules 获取用户的全部提醒规则
+func (l *GetAlertRulesLogic) GetAlertRules(req *types.GetAlertRulesReq) (resp *types.GetAlertRulesResp, err error) {
+	var rules []alertRule
+	err = l.svcCtx.DB.WithContext(l.ctx).Table(alertRulesTable).
+		Where("user_id = ?", req.UserID).
+		Order("id").
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &types.GetAlertRulesResp{
+		List: make([]types.AlertRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		resp.List = append(resp.List, toAlertRule(rule))
+	}
+	return resp, nil
+}
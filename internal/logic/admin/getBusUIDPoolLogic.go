@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetBusUIDPoolLogic struct {
+	logx.Logger
+	ctx         context.Context
+	svcCtx      *svc.ServiceContext
+	poolManager *busManager.UIDPoolManager
+}
+
+func NewGetBusUIDPoolLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusUIDPoolLogic {
+	return &GetBusUIDPoolLogic{
+		Logger:      logx.WithContext(ctx),
+		ctx:         ctx,
+		svcCtx:      svcCtx,
+		poolManager: busManager.NewUIDPoolManager(ctx, svcCtx),
+	}
+}
+
+func (l *GetBusUIDPoolLogic) GetBusUIDPool(req *types.GetBusUIDPoolReq) (resp *types.GetBusUIDPoolResp, err error) {
+	accounts, err := l.poolManager.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	resp = &types.GetBusUIDPoolResp{
+		List: make([]types.BusServiceAccount, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		status := "available"
+		if account.Quarantined(now) {
+			status = "quarantined"
+		} else {
+			resp.Available++
+		}
+		resp.List = append(resp.List, types.BusServiceAccount{
+			UID:                 account.UID,
+			Status:              status,
+			LastUsedAt:          formatMilli(account.LastUsedAt),
+			Successes:           account.Successes,
+			Failures:            account.Failures,
+			ConsecutiveFailures: account.ConsecutiveFailures,
+			QuarantinedUntil:    formatMilli(account.QuarantinedUntil),
+			LastError:           account.LastError,
+		})
+	}
+	return resp, nil
+}
+
+// formatMilli 格式化毫秒时间戳, 为 0 时返回空字符串
+func formatMilli(ms int64) string {
+	if ms == 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"context"
+
+	jobManager "yxy-go/internal/manager/job"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCronJobRunsLogic struct {
+	logx.Logger
+	ctx        context.Context
+	svcCtx     *svc.ServiceContext
+	jobManager *jobManager.JobManager
+}
+
+func NewGetCronJobRunsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCronJobRunsLogic {
+	return &GetCronJobRunsLogic{
+		Logger:     logx.WithContext(ctx),
+		ctx:        ctx,
+		svcCtx:     svcCtx,
+		jobManager: jobManager.NewJobManager(ctx, svcCtx),
+	}
+}
+
+func (l *GetCronJobRunsLogic) GetCronJobRuns(req *types.GetCronJobRunsReq) (resp *types.GetCronJobRunsResp, err error) {
+	job, err := l.jobManager.GetJob(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, xerr.WithCode(xerr.ErrJobNotFound, req.Name)
+	}
+	page := max(req.Page, 1)
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	runs, total, err := l.jobManager.History(req.Name, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	resp = &types.GetCronJobRunsResp{
+		Total: total,
+		List:  make([]types.CronJobRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		resp.List = append(resp.List, toCronJobRun(run))
+	}
+	return resp, nil
+}
+
+func toCronJobRun(run jobManager.JobRun) types.CronJobRun {
+	item := types.CronJobRun{
+		Name:      run.Name,
+		Trigger:   run.Trigger,
+		Status:    run.Status,
+		StartedAt: formatMilli(run.StartedAt),
+		EndedAt:   formatMilli(run.EndedAt),
+		Stats:     run.Stats,
+		Error:     run.Error,
+	}
+	if run.EndedAt > 0 {
+		item.DurationMs = run.EndedAt - run.StartedAt
+	}
+	return item
+}
@@ -0,0 +1,67 @@
+package admin
+
+import (
+	"context"
+	"sort"
+
+	jobManager "yxy-go/internal/manager/job"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCronJobsLogic struct {
+	logx.Logger
+	ctx        context.Context
+	svcCtx     *svc.ServiceContext
+	jobManager *jobManager.JobManager
+}
+
+func NewGetCronJobsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCronJobsLogic {
+	return &GetCronJobsLogic{
+		Logger:     logx.WithContext(ctx),
+		ctx:        ctx,
+		svcCtx:     svcCtx,
+		jobManager: jobManager.NewJobManager(ctx, svcCtx),
+	}
+}
+
+func (l *GetCronJobsLogic) GetCronJobs(req *types.GetCronJobsReq) (resp *types.GetCronJobsResp, err error) {
+	jobs, err := l.jobManager.ListJobs()
+	if err != nil {
+		return nil, err
+	}
+	running, err := l.jobManager.Running()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Name < jobs[j].Name
+	})
+
+	resp = &types.GetCronJobsResp{
+		List: make([]types.CronJob, 0, len(jobs)),
+	}
+	for _, job := range jobs {
+		_, isRunning := running[job.Name]
+		item := types.CronJob{
+			Name:     job.Name,
+			Schedule: job.Schedule,
+			Enabled:  job.Enabled,
+			Timeout:  job.Timeout.String(),
+			Overlap:  job.Overlap,
+			Running:  isRunning,
+		}
+		runs, _, err := l.jobManager.History(job.Name, 1, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) > 0 {
+			lastRun := toCronJobRun(runs[0])
+			item.LastRun = &lastRun
+		}
+		resp.List = append(resp.List, item)
+	}
+	return resp, nil
+}
@@ -0,0 +1,84 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm/clause"
+)
+
+const cardBalanceAlertSubscriptionsTable = "low_card_balance_alert_subscriptions"
+
+type SaveCardBalanceAlertSubscriptionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSaveCardBalanceAlertSubscriptionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveCardBalanceAlertSubscriptionLogic {
+	return &SaveCardBalanceAlertSubscriptionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+type cardBalanceAlertSubscription struct {
+	ID        int64   `gorm:"column:id"`
+	UserID    int64   `gorm:"column:user_id"`
+	DeviceID  string  `gorm:"column:device_id"`
+	Threshold float64 `gorm:"column:threshold"`
+	Count     int64   `gorm:"column:count"`
+}
+
+// SaveCardBalanceAlertSubscription 创建或更新用户的校园卡余额不足提醒订阅, 每个用户只有一条订阅
+func (l *SaveCardBalanceAlertSubscriptionLogic) SaveCardBalanceAlertSubscription(req *types.SaveCardBalanceAlertSubscriptionReq) (resp *types.SaveCardBalanceAlertSubscriptionResp, err error) {
+	if req.Threshold < 0 || req.Count < 0 {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("invalid threshold %v or count %v", req.Threshold, req.Count))
+	}
+	var users int64
+	if err = l.svcCtx.DB.WithContext(l.ctx).Table("users").Where("id = ?", req.UserID).Count(&users).Error; err != nil {
+		return nil, err
+	}
+	if users == 0 {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("user %v not found", req.UserID))
+	}
+
+	subscription := cardBalanceAlertSubscription{
+		UserID:    req.UserID,
+		DeviceID:  req.DeviceID,
+		Threshold: req.Threshold,
+		Count:     req.Count,
+	}
+	err = l.svcCtx.DB.WithContext(l.ctx).Table(cardBalanceAlertSubscriptionsTable).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"device_id", "threshold", "count"}),
+		}).
+		Create(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+	// 更新已有订阅时不会返回 ID, 重新查询
+	if err = l.svcCtx.DB.WithContext(l.ctx).Table(cardBalanceAlertSubscriptionsTable).
+		Where("user_id = ?", req.UserID).
+		Take(&subscription).Error; err != nil {
+		return nil, err
+	}
+	l.Logger.Infof("保存校园卡余额不足提醒订阅: user_id=%d, threshold=%v, count=%d", req.UserID, req.Threshold, req.Count)
+
+	return &types.SaveCardBalanceAlertSubscriptionResp{
+		Subscription: types.CardBalanceAlertSubscription{
+			ID:        subscription.ID,
+			UserID:    subscription.UserID,
+			DeviceID:  subscription.DeviceID,
+			Threshold: subscription.Threshold,
+			Count:     subscription.Count,
+		},
+	}, nil
+}
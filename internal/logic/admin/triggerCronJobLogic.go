@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"context"
+
+	jobManager "yxy-go/internal/manager/job"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type TriggerCronJobLogic struct {
+	logx.Logger
+	ctx        context.Context
+	svcCtx     *svc.ServiceContext
+	jobManager *jobManager.JobManager
+}
+
+func NewTriggerCronJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TriggerCronJobLogic {
+	return &TriggerCronJobLogic{
+		Logger:     logx.WithContext(ctx),
+		ctx:        ctx,
+		svcCtx:     svcCtx,
+		jobManager: jobManager.NewJobManager(ctx, svcCtx),
+	}
+}
+
+// TriggerCronJob 手动触发定时任务, 任务由定时服务从队列中取出后异步执行
+func (l *TriggerCronJobLogic) TriggerCronJob(req *types.TriggerCronJobReq) (resp *types.TriggerCronJobResp, err error) {
+	job, err := l.jobManager.GetJob(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, xerr.WithCode(xerr.ErrJobNotFound, req.Name)
+	}
+	if err = l.jobManager.Trigger(req.Name); err != nil {
+		return nil, err
+	}
+	l.Logger.Infof("手动触发定时任务: %s", req.Name)
+	return &types.TriggerCronJobResp{}, nil
+}
@@ -0,0 +1,163 @@
+package bus
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"yxy-go/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+// 校车公告变更类型
+const (
+	AnnouncementPublished = "published"
+	AnnouncementEdited    = "edited"
+)
+
+// 已见过的公告记录(hash, field 为公告 ID)和按变更时间排序的公告 ID(zset)
+const (
+	announcementSeenCacheKey    = "bus:announcement:seen"
+	announcementChangesCacheKey = "bus:announcement:changes"
+)
+
+// AnnouncementChange 新发布或被编辑的公告
+type AnnouncementChange struct {
+	Type         string
+	Hash         string
+	Announcement types.BusAnnouncement
+}
+
+// announcementRecord 已见过的公告, 保存最近一次的内容哈希
+type announcementRecord struct {
+	Hash         string                `json:"hash"`
+	Type         string                `json:"type"`
+	FirstSeenAt  int64                 `json:"first_seen_at"`
+	ChangedAt    int64                 `json:"changed_at"`
+	Announcement types.BusAnnouncement `json:"announcement"`
+}
+
+// announcementID 优先使用易校园返回的公告 ID; 没有 ID 时使用发布时间、作者和标题,
+// 同一时间同一作者发布的多条公告不会冲突, 但修改标题会被视为新公告
+func announcementID(upstreamID, publishedAt, author, title string) string {
+	if upstreamID != "" {
+		return upstreamID
+	}
+	sum := md5.Sum([]byte(publishedAt + "|" + author + "|" + title))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// announcementHash 根据标题、正文和内容块计算公告内容的哈希, 只改动图片或链接也视为编辑
+func announcementHash(a types.BusAnnouncement) string {
+	blocks, _ := jsonx.Marshal(a.Blocks)
+	sum := md5.Sum([]byte(a.Title + "\n" + a.Abstract + "\n" + strings.Join(a.Content, "\n") + "\n" + string(blocks)))
+	return hex.EncodeToString(sum[:])
+}
+
+// detectAnnouncementChanges 与已见过的公告哈希比较, 找出新发布和被编辑的公告
+func detectAnnouncementChanges(seen map[string]string, list []types.BusAnnouncement) []AnnouncementChange {
+	var changes []AnnouncementChange
+	for _, a := range list {
+		hash := announcementHash(a)
+		prev, ok := seen[a.ID]
+		switch {
+		case !ok:
+			changes = append(changes, AnnouncementChange{Type: AnnouncementPublished, Hash: hash, Announcement: a})
+		case prev != hash:
+			changes = append(changes, AnnouncementChange{Type: AnnouncementEdited, Hash: hash, Announcement: a})
+		}
+	}
+	return changes
+}
+
+// removedAnnouncementIDs 找出已不在易校园公告列表中的公告, 列表为空时视为获取异常, 不清理
+func removedAnnouncementIDs(records map[string]announcementRecord, list []types.BusAnnouncement) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	current := make(map[string]struct{}, len(list))
+	for _, a := range list {
+		current[a.ID] = struct{}{}
+	}
+	var removed []string
+	for id := range records {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// recordAnnouncementChanges 保存公告的变更并清理已下线的公告和过期的变更, 返回需要推送的变更;
+// 首次运行时只记录不推送, 避免推送全部历史公告
+func (l *GetBusAnnouncementLogic) recordAnnouncementChanges(list []types.BusAnnouncement, now int64) ([]AnnouncementChange, error) {
+	raws, err := l.svcCtx.Rdb.HGetAll(l.ctx, announcementSeenCacheKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]announcementRecord, len(raws))
+	seen := make(map[string]string, len(raws))
+	for id, raw := range raws {
+		var record announcementRecord
+		if err = jsonx.UnmarshalFromString(raw, &record); err != nil {
+			l.Logger.Errorf("校车公告记录反序列化失败: %v", err)
+			continue
+		}
+		records[id] = record
+		seen[id] = record.Hash
+	}
+
+	changes := detectAnnouncementChanges(seen, list)
+	removed := removedAnnouncementIDs(records, list)
+	retention := l.svcCtx.Config.BusService.AnnouncementRetention
+	_, err = l.svcCtx.Rdb.Pipelined(l.ctx, func(pipe redis.Pipeliner) error {
+		for i, change := range changes {
+			// 同一批变更的时间依次加 1ms, 保证游标分页时不会因时间相同而漏掉公告
+			changedAt := now + int64(i)
+			record := announcementRecord{
+				Hash:         change.Hash,
+				Type:         change.Type,
+				FirstSeenAt:  changedAt,
+				ChangedAt:    changedAt,
+				Announcement: change.Announcement,
+			}
+			if prev, ok := records[change.Announcement.ID]; ok {
+				record.FirstSeenAt = prev.FirstSeenAt
+			}
+			data, err := jsonx.MarshalToString(record)
+			if err != nil {
+				return err
+			}
+			pipe.HSet(l.ctx, announcementSeenCacheKey, change.Announcement.ID, data)
+			pipe.ZAdd(l.ctx, announcementChangesCacheKey, redis.Z{Score: float64(changedAt), Member: change.Announcement.ID})
+		}
+		if len(removed) > 0 {
+			members := make([]any, len(removed))
+			for i, id := range removed {
+				members[i] = id
+			}
+			pipe.HDel(l.ctx, announcementSeenCacheKey, removed...)
+			pipe.ZRem(l.ctx, announcementChangesCacheKey, members...)
+		}
+		pipe.ZRemRangeByScore(l.ctx, announcementChangesCacheKey, "-inf", "("+strconv.FormatInt(now-retention.Milliseconds(), 10))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(removed) > 0 {
+		l.Logger.Infof("清理已下线的校车公告 %d 条", len(removed))
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if len(records) == 0 {
+		l.Logger.Infof("首次记录校车公告 %d 条, 不推送", len(changes))
+		return nil, nil
+	}
+	return changes, nil
+}
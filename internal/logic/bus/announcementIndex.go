@@ -0,0 +1,274 @@
+package bus
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"yxy-go/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+const (
+	announcementIndexCacheKey        = "bus:announcement:index"
+	announcementIndexVersionCacheKey = "bus:announcement:index:version"
+)
+
+// loadedAnnouncementIndex 进程内缓存的公告搜索索引, 版本与 redis 中一致时不再重新读取和反序列化
+var loadedAnnouncementIndex struct {
+	sync.Mutex
+	version string
+	idx     *announcementIndex
+}
+
+// announcementIndex 公告搜索索引, 以单字和相邻两字为词项建立倒排表
+type announcementIndex struct {
+	Docs     []announcementDoc `json:"docs"` // 按发布时间倒序
+	Postings map[string][]int  `json:"postings"`
+}
+
+type announcementDoc struct {
+	Announcement types.BusAnnouncement `json:"announcement"`
+	Text         string                `json:"text"`         // 归一化后的标题、摘要和正文
+	PublishedAt  int64                 `json:"published_at"` // 解析失败时为 0
+	PublishedOn  string                `json:"published_on"` // 发布日期 2006-01-02
+}
+
+// announcementQuery 公告搜索条件, 均为空时返回全部公告
+type announcementQuery struct {
+	Keyword string
+	Author  string
+	From    string
+	To      string
+	Cursor  string
+	Limit   int
+}
+
+// buildAnnouncementIndex 建立公告搜索索引
+func buildAnnouncementIndex(list []types.BusAnnouncement) *announcementIndex {
+	idx := &announcementIndex{
+		Docs:     make([]announcementDoc, 0, len(list)),
+		Postings: make(map[string][]int),
+	}
+	for _, a := range list {
+		doc := announcementDoc{
+			Announcement: a,
+			Text:         normalizeSearch(announcementText(a)),
+			PublishedOn:  dateOf(a.PublishedAt),
+		}
+		if t, err := parseDepartureTime(a.PublishedAt); err == nil {
+			doc.PublishedAt = t.Unix()
+		}
+		idx.Docs = append(idx.Docs, doc)
+	}
+	sort.SliceStable(idx.Docs, func(i, j int) bool {
+		return docBefore(idx.Docs[i], idx.Docs[j])
+	})
+
+	for i, doc := range idx.Docs {
+		for token := range tokenize(doc.Text) {
+			idx.Postings[token] = append(idx.Postings[token], i)
+		}
+	}
+	return idx
+}
+
+// announcementText 公告中可搜索的文字
+func announcementText(a types.BusAnnouncement) string {
+	parts := []string{a.Title, a.Abstract}
+	parts = append(parts, a.Content...)
+	for _, block := range a.Blocks {
+		parts = append(parts, block.Text)
+		for _, row := range block.Rows {
+			parts = append(parts, row...)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// tokenize 将文本切分为单字和相邻两字的词项
+func tokenize(text string) map[string]struct{} {
+	runes := []rune(text)
+	tokens := make(map[string]struct{}, len(runes)*2)
+	for i := range runes {
+		tokens[string(runes[i])] = struct{}{}
+		if i+1 < len(runes) {
+			tokens[string(runes[i:i+2])] = struct{}{}
+		}
+	}
+	return tokens
+}
+
+// docBefore 排序规则: 发布时间倒序, 相同时按 ID 倒序, 保证游标稳定
+func docBefore(a, b announcementDoc) bool {
+	if a.PublishedAt != b.PublishedAt {
+		return a.PublishedAt > b.PublishedAt
+	}
+	return a.Announcement.ID > b.Announcement.ID
+}
+
+func docCursor(doc announcementDoc) string {
+	return fmt.Sprintf("%d_%s", doc.PublishedAt, doc.Announcement.ID)
+}
+
+func parseDocCursor(cursor string) (announcementDoc, error) {
+	publishedAt, id, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return announcementDoc{}, fmt.Errorf("invalid cursor: %v", cursor)
+	}
+	t, err := strconv.ParseInt(publishedAt, 10, 64)
+	if err != nil {
+		return announcementDoc{}, fmt.Errorf("invalid cursor: %v", cursor)
+	}
+	return announcementDoc{PublishedAt: t, Announcement: types.BusAnnouncement{ID: id}}, nil
+}
+
+// candidates 根据关键字的词项取倒排表交集, 无关键字时返回全部文档
+func (idx *announcementIndex) candidates(keyword string) []int {
+	if keyword == "" {
+		all := make([]int, len(idx.Docs))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	runes := []rune(keyword)
+	var tokens []string
+	if len(runes) == 1 {
+		tokens = []string{keyword}
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		tokens = append(tokens, string(runes[i:i+2]))
+	}
+
+	var result []int
+	for i, token := range tokens {
+		postings := idx.Postings[token]
+		if i == 0 {
+			result = postings
+			continue
+		}
+		result = intersect(result, postings)
+		if len(result) == 0 {
+			break
+		}
+	}
+	return result
+}
+
+func intersect(a, b []int) []int {
+	result := make([]int, 0, min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return result
+}
+
+// search 按条件搜索公告, 返回当前页、匹配总数和下一页的游标
+func (idx *announcementIndex) search(q announcementQuery) ([]types.BusAnnouncement, int, string, error) {
+	var after *announcementDoc
+	if q.Cursor != "" {
+		doc, err := parseDocCursor(q.Cursor)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		after = &doc
+	}
+
+	keyword := normalizeSearch(q.Keyword)
+	var matched []announcementDoc
+	for _, i := range idx.candidates(keyword) {
+		doc := idx.Docs[i]
+		// 倒排表只保证包含全部词项, 需要再确认包含完整的关键字
+		if keyword != "" && !strings.Contains(doc.Text, keyword) {
+			continue
+		}
+		if q.Author != "" && !strings.Contains(doc.Announcement.Author, q.Author) {
+			continue
+		}
+		if q.From != "" && doc.PublishedOn < q.From {
+			continue
+		}
+		if q.To != "" && doc.PublishedOn > q.To {
+			continue
+		}
+		matched = append(matched, doc)
+	}
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return docBefore(*after, matched[i])
+		})
+	}
+	end := min(start+q.Limit, len(matched))
+	list := make([]types.BusAnnouncement, 0, end-start)
+	for _, doc := range matched[start:end] {
+		list = append(list, doc.Announcement)
+	}
+	var next string
+	if end < len(matched) {
+		next = docCursor(matched[end-1])
+	}
+	return list, len(matched), next, nil
+}
+
+func indexVersion(data string) string {
+	sum := md5.Sum([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// rebuildAnnouncementIndex 重建公告搜索索引, 同时更新索引版本
+func (l *GetBusAnnouncementLogic) rebuildAnnouncementIndex(list []types.BusAnnouncement) error {
+	data, err := jsonx.MarshalToString(buildAnnouncementIndex(list))
+	if err != nil {
+		return err
+	}
+	_, err = l.svcCtx.Rdb.TxPipelined(l.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(l.ctx, announcementIndexCacheKey, data, 0)
+		pipe.Set(l.ctx, announcementIndexVersionCacheKey, indexVersion(data), 0)
+		return nil
+	})
+	return err
+}
+
+// getAnnouncementIndex 读取公告搜索索引, 只有版本变化时才从 redis 重新加载; 索引或版本不存在时返回 redis.Nil
+func (l *GetBusAnnouncementLogic) getAnnouncementIndex() (*announcementIndex, error) {
+	version, err := l.svcCtx.Rdb.Get(l.ctx, announcementIndexVersionCacheKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	loadedAnnouncementIndex.Lock()
+	defer loadedAnnouncementIndex.Unlock()
+	if loadedAnnouncementIndex.idx != nil && loadedAnnouncementIndex.version == version {
+		return loadedAnnouncementIndex.idx, nil
+	}
+
+	raw, err := l.svcCtx.Rdb.Get(l.ctx, announcementIndexCacheKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	var idx announcementIndex
+	if err = jsonx.UnmarshalFromString(raw, &idx); err != nil {
+		return nil, err
+	}
+	// 版本按实际读到的内容计算, 读取期间索引被重建时下次请求会重新加载
+	loadedAnnouncementIndex.version = indexVersion(raw)
+	loadedAnnouncementIndex.idx = &idx
+	return &idx, nil
+}
@@ -0,0 +1,54 @@
+package bus
+
+import (
+	"testing"
+
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectAnnouncementChanges(t *testing.T) {
+	unchanged := types.BusAnnouncement{ID: announcementID("", "2024-09-01 08:00:00", "后勤", "开学班车安排"), Title: "开学班车安排"}
+	edited := types.BusAnnouncement{ID: announcementID("", "2024-09-02 08:00:00", "后勤", "国庆停运通知"), Title: "国庆停运通知", Content: []string{"10月1日至7日停运"}}
+	published := types.BusAnnouncement{ID: announcementID("", "2024-09-03 08:00:00", "后勤", "新增线路"), Title: "新增线路"}
+	seen := map[string]string{
+		unchanged.ID: announcementHash(unchanged),
+		edited.ID:    announcementHash(types.BusAnnouncement{Title: "国庆停运通知"}),
+	}
+
+	changes := detectAnnouncementChanges(seen, []types.BusAnnouncement{unchanged, edited, published})
+	assert.Len(t, changes, 2)
+	assert.Equal(t, AnnouncementEdited, changes[0].Type)
+	assert.Equal(t, edited.ID, changes[0].Announcement.ID)
+	assert.Equal(t, AnnouncementPublished, changes[1].Type)
+	assert.Equal(t, announcementHash(published), changes[1].Hash)
+	assert.NotEqual(t, unchanged.ID, edited.ID)
+}
+
+func TestAnnouncementID(t *testing.T) {
+	assert.Equal(t, "42", announcementID("42", "2024-09-01 08:00:00", "后勤", "开学班车安排"))
+	assert.NotEqual(t,
+		announcementID("", "2024-09-01 08:00:00", "后勤", "开学班车安排"),
+		announcementID("", "2024-09-01 08:00:00", "后勤", "国庆停运通知"))
+}
+
+func TestAnnouncementHashBlocks(t *testing.T) {
+	a := types.BusAnnouncement{Title: "时刻表", Blocks: []types.BusAnnouncementBlock{{Type: "image", URL: "https://example.com/a.png"}}}
+	b := a
+	b.Blocks = []types.BusAnnouncementBlock{{Type: "image", URL: "https://example.com/b.png"}}
+	assert.NotEqual(t, announcementHash(a), announcementHash(b))
+}
+
+func TestRemovedAnnouncementIDs(t *testing.T) {
+	records := map[string]announcementRecord{
+		"1": {Hash: "a"},
+		"2": {Hash: "b"},
+		"3": {Hash: "c"},
+	}
+	list := []types.BusAnnouncement{{ID: "2"}, {ID: "4"}}
+
+	assert.Equal(t, []string{"1", "3"}, removedAnnouncementIDs(records, list))
+	assert.Empty(t, removedAnnouncementIDs(records, nil))
+	assert.Empty(t, removedAnnouncementIDs(nil, list))
+}
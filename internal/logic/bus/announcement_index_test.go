@@ -0,0 +1,47 @@
+package bus
+
+import (
+	"testing"
+
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncementIndexSearch(t *testing.T) {
+	idx := buildAnnouncementIndex([]types.BusAnnouncement{
+		{ID: "a", Title: "开学班车安排", Author: "后勤", PublishedAt: "2024-09-01 08:00:00"},
+		{ID: "b", Title: "国庆停运通知", Author: "后勤", PublishedAt: "2024-09-20 08:00:00", Content: []string{"屏峰线路暂停"}},
+		{ID: "c", Title: "屏峰校区临时加班车", Author: "屏峰校区", PublishedAt: "2024-09-20 08:00:00"},
+		{ID: "d", Title: "班车时刻表", Author: "后勤", PublishedAt: "2024-10-08 08:00:00",
+			Blocks: []types.BusAnnouncementBlock{{Type: "table", Rows: [][]string{{"屏峰", "07:30"}}}}},
+	})
+	ids := func(list []types.BusAnnouncement) []string {
+		result := make([]string, len(list))
+		for i, a := range list {
+			result[i] = a.ID
+		}
+		return result
+	}
+
+	list, total, next, err := idx.search(announcementQuery{Keyword: "屏峰", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, []string{"d", "c"}, ids(list))
+	assert.NotEmpty(t, next)
+
+	list, _, next, err = idx.search(announcementQuery{Keyword: "屏峰", Limit: 2, Cursor: next})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids(list))
+	assert.Empty(t, next)
+
+	list, _, _, _ = idx.search(announcementQuery{Author: "后勤", From: "2024-09-01", To: "2024-09-30", Limit: 10})
+	assert.Equal(t, []string{"b", "a"}, ids(list))
+
+	list, total, _, _ = idx.search(announcementQuery{Keyword: "停车", Limit: 10})
+	assert.Empty(t, list)
+	assert.Zero(t, total)
+
+	_, _, _, err = idx.search(announcementQuery{Cursor: "bad", Limit: 10})
+	assert.Error(t, err)
+}
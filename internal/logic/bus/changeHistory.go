@@ -0,0 +1,211 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"yxy-go/internal/types"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/jsonx"
+)
+
+// 校车信息变更类型
+const (
+	ChangeSeats            = "seats_changed"
+	ChangeDepartureAdded   = "departure_added"
+	ChangeDepartureRemoved = "departure_removed"
+	ChangeRouteAdded       = "route_added"
+	ChangeRouteRemoved     = "route_removed"
+)
+
+const (
+	busChangesCacheKey   = "bus:info:changes"
+	busChangedAtCacheKey = "bus:info:changed_at"
+)
+
+func seatHistoryCacheKey(busID, departureTime string) string {
+	return fmt.Sprintf("bus:seat_history:%s:%s", busID, departureTime)
+}
+
+// DiffBusInfo 比较前后两次爬取的校车信息, 生成变更事件
+func DiffBusInfo(prev, curr []types.BusInfo, at int64) []types.BusChangeEvent {
+	prevRoutes := make(map[string]types.BusInfo, len(prev))
+	for _, info := range prev {
+		prevRoutes[info.ID] = info
+	}
+
+	var events []types.BusChangeEvent
+	currRoutes := make(map[string]struct{}, len(curr))
+	for _, info := range curr {
+		currRoutes[info.ID] = struct{}{}
+		prevInfo, ok := prevRoutes[info.ID]
+		if !ok {
+			events = append(events, types.BusChangeEvent{
+				Type:  ChangeRouteAdded,
+				BusID: info.ID,
+				Name:  info.Name,
+				Time:  at,
+			})
+		}
+
+		prevTimes := make(map[string]types.BusTime, len(prevInfo.BusTime))
+		for _, t := range prevInfo.BusTime {
+			prevTimes[t.DepartureTime] = t
+		}
+		currTimes := make(map[string]struct{}, len(info.BusTime))
+		for _, t := range info.BusTime {
+			currTimes[t.DepartureTime] = struct{}{}
+			event := types.BusChangeEvent{
+				BusID:         info.ID,
+				Name:          info.Name,
+				DepartureTime: t.DepartureTime,
+				RemainSeats:   t.RemainSeats,
+				OrderedSeats:  t.OrderedSeats,
+				Time:          at,
+			}
+			prevTime, ok := prevTimes[t.DepartureTime]
+			switch {
+			case !ok:
+				event.Type = ChangeDepartureAdded
+				event.Delta = t.RemainSeats
+			case prevTime.RemainSeats != t.RemainSeats || prevTime.OrderedSeats != t.OrderedSeats:
+				event.Type = ChangeSeats
+				event.Delta = t.RemainSeats - prevTime.RemainSeats
+			default:
+				continue
+			}
+			events = append(events, event)
+		}
+		for _, t := range prevInfo.BusTime {
+			if _, ok := currTimes[t.DepartureTime]; !ok {
+				events = append(events, types.BusChangeEvent{
+					Type:          ChangeDepartureRemoved,
+					BusID:         info.ID,
+					Name:          info.Name,
+					DepartureTime: t.DepartureTime,
+					Delta:         -t.RemainSeats,
+					Time:          at,
+				})
+			}
+		}
+	}
+
+	for _, info := range prev {
+		if _, ok := currRoutes[info.ID]; !ok {
+			events = append(events, types.BusChangeEvent{
+				Type:  ChangeRouteRemoved,
+				BusID: info.ID,
+				Name:  info.Name,
+				Time:  at,
+			})
+		}
+	}
+	return events
+}
+
+// recordChanges 保存变更事件和班次余票历史, 超过保留时长的记录会被清理
+func (l *GetBusInfoLogic) recordChanges(events []types.BusChangeEvent, at int64) error {
+	if len(events) == 0 {
+		return nil
+	}
+	retention := l.svcCtx.Config.BusService.ChangeRetention
+	_, err := l.svcCtx.Rdb.Pipelined(l.ctx, func(pipe redis.Pipeliner) error {
+		for _, event := range events {
+			data, err := jsonx.MarshalToString(event)
+			if err != nil {
+				return err
+			}
+			pipe.ZAdd(l.ctx, busChangesCacheKey, redis.Z{Score: float64(at), Member: data})
+
+			if event.Type != ChangeSeats && event.Type != ChangeDepartureAdded {
+				continue
+			}
+			point, err := jsonx.MarshalToString(types.BusSeatPoint{
+				Time:         at,
+				RemainSeats:  event.RemainSeats,
+				OrderedSeats: event.OrderedSeats,
+			})
+			if err != nil {
+				return err
+			}
+			key := seatHistoryCacheKey(event.BusID, event.DepartureTime)
+			pipe.ZAdd(l.ctx, key, redis.Z{Score: float64(at), Member: point})
+			pipe.Expire(l.ctx, key, retention)
+		}
+		pipe.ZRemRangeByScore(l.ctx, busChangesCacheKey, "-inf", "("+strconv.FormatInt(at-retention.Milliseconds(), 10))
+		pipe.Set(l.ctx, busChangedAtCacheKey, at, 0)
+		return nil
+	})
+	return err
+}
+
+// getChangesSince 获取 since(不含) 之后的变更事件, 按时间升序
+func (l *GetBusInfoLogic) getChangesSince(since int64) ([]types.BusChangeEvent, error) {
+	raws, err := l.svcCtx.Rdb.ZRangeByScore(l.ctx, busChangesCacheKey, &redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(since, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]types.BusChangeEvent, 0, len(raws))
+	for _, raw := range raws {
+		var event types.BusChangeEvent
+		if err = jsonx.UnmarshalFromString(raw, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// getLastChangedAt 获取最近一次发生变更的时间, 从未变更时返回 0
+func (l *GetBusInfoLogic) getLastChangedAt() (int64, error) {
+	changedAt, err := l.svcCtx.Rdb.Get(l.ctx, busChangedAtCacheKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return changedAt, err
+}
+
+// changesRetained 判断 since 之后的变更记录是否都还保留着, 早于 now - retention 的记录可能已被清理
+func changesRetained(since, now int64, retention time.Duration) bool {
+	return since >= now-retention.Milliseconds()
+}
+
+// applyChangedSince 只保留 since 之后有变更的线路, 并附带这些线路的变更事件;
+// since 之后的变更记录可能已被清理时返回全部线路, 并设置 FullResync 提示客户端全量刷新
+func (l *GetBusInfoLogic) applyChangedSince(resp *types.GetBusInfoResp, since int64) error {
+	if !changesRetained(since, time.Now().UnixMilli(), l.svcCtx.Config.BusService.ChangeRetention) {
+		resp.FullResync = true
+		return nil
+	}
+	events, err := l.getChangesSince(since)
+	if err != nil {
+		return err
+	}
+	inList := make(map[string]struct{}, len(resp.List))
+	for _, info := range resp.List {
+		inList[info.ID] = struct{}{}
+	}
+	changed := make(map[string]struct{}, len(events))
+	changes := make([]types.BusChangeEvent, 0, len(events))
+	for _, event := range events {
+		// 已删除的线路不在列表中, 也需要告知客户端
+		if _, ok := inList[event.BusID]; ok || event.Type == ChangeRouteRemoved {
+			changed[event.BusID] = struct{}{}
+			changes = append(changes, event)
+		}
+	}
+	list := make([]types.BusInfo, 0, len(changed))
+	for _, info := range resp.List {
+		if _, ok := changed[info.ID]; ok {
+			list = append(list, info)
+		}
+	}
+	resp.List = list
+	resp.Changes = changes
+	return nil
+}
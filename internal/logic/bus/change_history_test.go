@@ -0,0 +1,49 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBusInfo(t *testing.T) {
+	prev := []types.BusInfo{
+		{ID: "1", Name: "A", BusTime: []types.BusTime{
+			{DepartureTime: "2024-09-02 07:30:00", RemainSeats: 5, OrderedSeats: 10},
+			{DepartureTime: "2024-09-02 17:30:00", RemainSeats: 3, OrderedSeats: 2},
+		}},
+		{ID: "2", Name: "B"},
+	}
+	curr := []types.BusInfo{
+		{ID: "1", Name: "A", BusTime: []types.BusTime{
+			{DepartureTime: "2024-09-02 07:30:00", RemainSeats: 2, OrderedSeats: 13},
+			{DepartureTime: "2024-09-03 07:30:00", RemainSeats: 15},
+		}},
+		{ID: "3", Name: "C"},
+	}
+
+	events := DiffBusInfo(prev, curr, 100)
+	kinds := make([]string, len(events))
+	for i, e := range events {
+		kinds[i] = e.Type
+		assert.Equal(t, int64(100), e.Time)
+	}
+	assert.Equal(t, []string{ChangeSeats, ChangeDepartureAdded, ChangeDepartureRemoved, ChangeRouteAdded, ChangeRouteRemoved}, kinds)
+	assert.Equal(t, -3, events[0].Delta)
+	assert.Equal(t, 15, events[1].Delta)
+	assert.Equal(t, "2024-09-02 17:30:00", events[2].DepartureTime)
+
+	assert.Empty(t, DiffBusInfo(curr, curr, 200))
+}
+
+func TestChangesRetained(t *testing.T) {
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.Local).UnixMilli()
+	retention := 72 * time.Hour
+
+	assert.True(t, changesRetained(now-time.Hour.Milliseconds(), now, retention))
+	assert.True(t, changesRetained(now-retention.Milliseconds(), now, retention))
+	assert.False(t, changesRetained(now-retention.Milliseconds()-1, now, retention))
+}
@@ -0,0 +1,297 @@
+package bus
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/metric"
+	"github.com/zeromicro/go-zero/core/mr"
+	"golang.org/x/time/rate"
+)
+
+var (
+	crawlDuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: "yxy",
+		Subsystem: "bus_crawler",
+		Name:      "duration_ms",
+		Help:      "bus info crawl duration(ms).",
+		Labels:    []string{"result"},
+		Buckets:   []float64{500, 1000, 2500, 5000, 10000, 30000, 60000},
+	})
+	crawlRequests = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "yxy",
+		Subsystem: "bus_crawler",
+		Name:      "requests_total",
+		Help:      "bus crawler upstream requests.",
+		Labels:    []string{"endpoint", "result"},
+	})
+	crawlRoutes = metric.NewGaugeVec(&metric.GaugeVecOpts{
+		Namespace: "yxy",
+		Subsystem: "bus_crawler",
+		Name:      "routes",
+		Help:      "bus routes of the last crawl by status.",
+		Labels:    []string{"status"},
+	})
+)
+
+// hostLimiters 每个上游 host 一个限流器, 所有爬取协程共享
+var hostLimiters sync.Map
+
+func observeCrawlRequest(endpoint string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "fail"
+	}
+	crawlRequests.Inc(endpoint, result)
+}
+
+// waitRateLimit 按 host 限流, 未配置速率时不限制
+func (l *GetBusInfoLogic) waitRateLimit(rawURL string) error {
+	conf := l.svcCtx.Config.BusService
+	if conf.CrawlRateLimit <= 0 {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	limiter, _ := hostLimiters.LoadOrStore(u.Host, rate.NewLimiter(rate.Limit(conf.CrawlRateLimit), max(conf.CrawlBurst, 1)))
+	if err = limiter.(*rate.Limiter).Wait(l.ctx); err != nil {
+		return xerr.WithCode(xerr.ErrHttpClient, err.Error())
+	}
+	return nil
+}
+
+type routeSchedules struct {
+	index     int
+	schedules []FetchBusScheduleYxyResp
+	err       error
+}
+
+type scheduleTask struct {
+	route      int
+	schedule   int
+	busID      string
+	scheduleID string
+}
+
+type scheduleDates struct {
+	scheduleTask
+	dates []types.BusDateSeats
+	err   error
+}
+
+// FetchAllBusData 获取全量校车信息和时刻表; 时刻表保留每个班次的全部日期, 校车信息中只保留最近的日期
+// 班次和预约情况由有限并发的协程池爬取, 单条线路失败时沿用上一次的数据, 不影响其他线路
+func (l *GetBusInfoLogic) FetchAllBusData(token string) ([]types.BusInfo, []types.BusTimetable, error) {
+	start := time.Now()
+	busInfoListRaw, err := l.fetchBusList(token, "")
+	if err != nil {
+		l.Logger.Errorf("获取校车信息失败, http 请求失败")
+		crawlDuration.Observe(time.Since(start).Milliseconds(), "fail")
+		return nil, nil, err
+	}
+	routes := busInfoListRaw.Results
+	workers := mr.WithWorkers(l.svcCtx.Config.BusService.CrawlConcurrency)
+
+	// 第一轮: 并发获取每条线路的班次
+	scheduleResults, err := mr.MapReduce(func(source chan<- int) {
+		for i := range routes {
+			source <- i
+		}
+	}, func(i int, writer mr.Writer[routeSchedules], cancel func(error)) {
+		schedules, err := l.fetchBusSchedule(token, routes[i].ID)
+		writer.Write(routeSchedules{index: i, schedules: schedules, err: err})
+	}, func(pipe <-chan routeSchedules, writer mr.Writer[[]routeSchedules], cancel func(error)) {
+		results := make([]routeSchedules, len(routes))
+		for r := range pipe {
+			results[r.index] = r
+		}
+		writer.Write(results)
+	}, mr.WithContext(l.ctx), workers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 第二轮: 并发获取每个班次各个日期的预约情况
+	// token 失效时不沿用旧数据, 直接返回错误, 由调用方隔离服务账号或刷新 token
+	var tasks []scheduleTask
+	for i, r := range scheduleResults {
+		if isBusTokenInvalid(r.err) {
+			crawlDuration.Observe(time.Since(start).Milliseconds(), "fail")
+			return nil, nil, r.err
+		}
+		if r.err != nil {
+			continue
+		}
+		for j, schedule := range r.schedules {
+			tasks = append(tasks, scheduleTask{route: i, schedule: j, busID: routes[i].ID, scheduleID: schedule.ID})
+		}
+	}
+	datesResults, err := mr.MapReduce(func(source chan<- scheduleTask) {
+		for _, task := range tasks {
+			source <- task
+		}
+	}, func(task scheduleTask, writer mr.Writer[scheduleDates], cancel func(error)) {
+		dates, err := l.fetchBusReservation(token, task.busID, task.scheduleID)
+		writer.Write(scheduleDates{scheduleTask: task, dates: dates, err: err})
+	}, func(pipe <-chan scheduleDates, writer mr.Writer[map[int][]scheduleDates], cancel func(error)) {
+		results := make(map[int][]scheduleDates)
+		for r := range pipe {
+			results[r.route] = append(results[r.route], r)
+		}
+		writer.Write(results)
+	}, mr.WithContext(l.ctx), workers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, dates := range datesResults {
+		for _, d := range dates {
+			if isBusTokenInvalid(d.err) {
+				crawlDuration.Observe(time.Since(start).Milliseconds(), "fail")
+				return nil, nil, d.err
+			}
+		}
+	}
+
+	lastInfo, lastTimetables := l.lastKnownBusData()
+	busInfoList := make([]types.BusInfo, 0, len(routes))
+	timetables := make([]types.BusTimetable, 0, len(routes))
+	var stale, failed int
+	for i, raw := range routes {
+		routeErr := scheduleResults[i].err
+		dates := datesResults[i]
+		for _, d := range dates {
+			if d.err != nil && routeErr == nil {
+				routeErr = d.err
+			}
+		}
+		if routeErr == nil {
+			info, timetable := buildRoute(raw, scheduleResults[i].schedules, dates)
+			busInfoList = append(busInfoList, info)
+			timetables = append(timetables, timetable)
+			continue
+		}
+
+		info, ok := lastInfo[raw.ID]
+		timetable, timetableOK := lastTimetables[raw.ID]
+		if !ok || !timetableOK {
+			l.Logger.Errorf("获取校车线路 %s 失败且无历史数据, 跳过: %v", raw.Name, routeErr)
+			failed++
+			continue
+		}
+		l.Logger.Errorf("获取校车线路 %s 失败, 沿用上一次的数据: %v", raw.Name, routeErr)
+		busInfoList = append(busInfoList, info)
+		timetables = append(timetables, timetable)
+		stale++
+	}
+
+	fresh := len(routes) - stale - failed
+	crawlRoutes.Set(float64(fresh), "fresh")
+	crawlRoutes.Set(float64(stale), "stale")
+	crawlRoutes.Set(float64(failed), "failed")
+	elapsed := time.Since(start)
+	l.Logger.Infof("校车信息爬取完成, 耗时 %v, 线路 %d 条 (成功 %d, 沿用旧数据 %d, 失败 %d), 班次 %d 个",
+		elapsed, len(routes), fresh, stale, failed, len(tasks))
+	if len(routes) > 0 && fresh == 0 {
+		crawlDuration.Observe(elapsed.Milliseconds(), "fail")
+		return nil, nil, xerr.WithCode(xerr.ErrHttpClient, "所有校车线路获取失败")
+	}
+	crawlDuration.Observe(elapsed.Milliseconds(), "ok")
+	return busInfoList, timetables, nil
+}
+
+// buildRoute 组装单条线路的校车信息和时刻表
+func buildRoute(raw busRouteYxy, schedules []FetchBusScheduleYxyResp, results []scheduleDates) (types.BusInfo, types.BusTimetable) {
+	info := types.BusInfo{
+		ID:       raw.ID,
+		Name:     raw.Name,
+		Price:    raw.Price,
+		Stations: make([]string, len(raw.Station)),
+		BusTime:  make([]types.BusTime, 0, len(schedules)),
+	}
+	timetable := types.BusTimetable{
+		RouteID:   raw.ID,
+		Name:      raw.Name,
+		Price:     raw.Price,
+		Stations:  make([]types.BusStation, len(raw.Station)),
+		Schedules: make([]types.BusSchedule, 0, len(schedules)),
+	}
+	for j, station := range raw.Station {
+		info.Stations[j] = station.Name
+		timetable.Stations[j] = types.BusStation{
+			ID:   station.ID,
+			Name: station.Name,
+			Seq:  station.Order,
+		}
+	}
+	sort.SliceStable(timetable.Stations, func(a, b int) bool {
+		return timetable.Stations[a].Seq < timetable.Stations[b].Seq
+	})
+	if n := len(timetable.Stations); n > 0 {
+		timetable.Direction = timetable.Stations[0].Name + "→" + timetable.Stations[n-1].Name
+	}
+
+	// 按班次原顺序输出
+	sort.Slice(results, func(a, b int) bool {
+		return results[a].schedule < results[b].schedule
+	})
+	for _, r := range results {
+		if len(r.dates) == 0 {
+			continue
+		}
+		timetable.Schedules = append(timetable.Schedules, types.BusSchedule{
+			ID:            r.scheduleID,
+			DepartureTime: clockOf(schedules[r.schedule].DepartureTime),
+			Dates:         r.dates,
+		})
+		first := r.dates[0]
+		if first.OrderedSeats == 0 && first.RemainSeats == 0 {
+			continue
+		}
+		info.BusTime = append(info.BusTime, types.BusTime{
+			DepartureTime: first.DepartureTime,
+			RemainSeats:   first.RemainSeats,
+			OrderedSeats:  first.OrderedSeats,
+		})
+	}
+	return info, timetable
+}
+
+// lastKnownBusData 读取缓存中上一次的校车信息和时刻表, 按线路 ID 索引
+func (l *GetBusInfoLogic) lastKnownBusData() (map[string]types.BusInfo, map[string]types.BusTimetable) {
+	infoByID := make(map[string]types.BusInfo)
+	timetableByID := make(map[string]types.BusTimetable)
+
+	prev, err := l.getBusInfoFromCache(func(_ types.BusInfo) bool {
+		return true
+	})
+	if err != nil {
+		l.Logger.Infof("获取上一次的校车信息失败: %v", err)
+	} else {
+		for _, info := range prev.List {
+			infoByID[info.ID] = info
+		}
+	}
+
+	raw, err := l.svcCtx.Rdb.Get(l.ctx, busTimetableCacheKey).Result()
+	if err != nil {
+		l.Logger.Infof("获取上一次的校车时刻表失败: %v", err)
+		return infoByID, timetableByID
+	}
+	var timetables []types.BusTimetable
+	if err = json.Unmarshal([]byte(raw), &timetables); err != nil {
+		l.Logger.Errorf("解析上一次的校车时刻表失败: %v", err)
+		return infoByID, timetableByID
+	}
+	for _, timetable := range timetables {
+		timetableByID[timetable.RouteID] = timetable
+	}
+	return infoByID, timetableByID
+}
@@ -0,0 +1,59 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateBusSeatWatchLogic struct {
+	logx.Logger
+	ctx          context.Context
+	svcCtx       *svc.ServiceContext
+	watchManager *busManager.SeatWatchManager
+}
+
+func NewCreateBusSeatWatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateBusSeatWatchLogic {
+	return &CreateBusSeatWatchLogic{
+		Logger:       logx.WithContext(ctx),
+		ctx:          ctx,
+		svcCtx:       svcCtx,
+		watchManager: busManager.NewSeatWatchManager(ctx, svcCtx),
+	}
+}
+
+func (l *CreateBusSeatWatchLogic) CreateBusSeatWatch(req *types.CreateBusSeatWatchReq) (resp *types.CreateBusSeatWatchResp, err error) {
+	busInfo, err := NewGetBusInfoLogic(l.ctx, l.svcCtx).getBusInfoFromCache(func(info types.BusInfo) bool {
+		return info.ID == req.BusID
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(busInfo.List) == 0 {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("bus not found: %v", req.BusID))
+	}
+
+	watch, err := l.watchManager.Create(req.Uid, req.BusID, req.DepartureTime)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CreateBusSeatWatchResp{
+		Watch: toBusSeatWatch(watch),
+	}, nil
+}
+
+func toBusSeatWatch(watch *busManager.SeatWatch) types.BusSeatWatch {
+	return types.BusSeatWatch{
+		ID:            watch.ID,
+		BusID:         watch.BusID,
+		DepartureTime: watch.DepartureTime,
+		CreatedAt:     time.Unix(watch.CreatedAt, 0).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,34 @@
+package bus
+
+import (
+	"context"
+
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteBusSeatWatchLogic struct {
+	logx.Logger
+	ctx          context.Context
+	svcCtx       *svc.ServiceContext
+	watchManager *busManager.SeatWatchManager
+}
+
+func NewDeleteBusSeatWatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteBusSeatWatchLogic {
+	return &DeleteBusSeatWatchLogic{
+		Logger:       logx.WithContext(ctx),
+		ctx:          ctx,
+		svcCtx:       svcCtx,
+		watchManager: busManager.NewSeatWatchManager(ctx, svcCtx),
+	}
+}
+
+func (l *DeleteBusSeatWatchLogic) DeleteBusSeatWatch(req *types.DeleteBusSeatWatchReq) (resp *types.DeleteBusSeatWatchResp, err error) {
+	if err = l.watchManager.Delete(req.Uid, req.ID); err != nil {
+		return nil, err
+	}
+	return &types.DeleteBusSeatWatchResp{}, nil
+}
@@ -0,0 +1,87 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/internal/utils/exporter"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// busRideDuration 日历中每趟校车占用的时长
+const busRideDuration = time.Hour
+
+type ExportBusReservationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportBusReservationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportBusReservationLogic {
+	return &ExportBusReservationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportBusReservationLogic) ExportBusReservation(req *types.ExportBusReservationReq) (*exporter.File, error) {
+	resp, err := NewGetBusReservationLogic(l.ctx, l.svcCtx).GetBusReservation(&types.GetBusReservationReq{
+		Uid:      req.Uid,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("bus_reservation_%v", req.Uid)
+	if req.Format == exporter.FormatICS {
+		return exporter.ExportCalendar(name, "校车预约", l.toEvents(resp.List, time.Now())), nil
+	}
+
+	table := exporter.Table{Headers: []string{"班次", "发车时间", "支付时间"}}
+	for _, record := range resp.List {
+		table.Rows = append(table.Rows, []string{record.Name, record.DepartureTime, record.PayTime})
+	}
+	return exporter.ExportTable(req.Format, name, table, resp)
+}
+
+// toEvents 将未发车的预约转换为日历事件
+func (l *ExportBusReservationLogic) toEvents(records []types.BusRecord, now time.Time) []exporter.Event {
+	events := make([]exporter.Event, 0, len(records))
+	for _, record := range records {
+		departure, err := parseDepartureTime(record.DepartureTime)
+		if err != nil {
+			l.Logger.Errorf("解析发车时间失败: %v", err)
+			continue
+		}
+		if departure.Before(now) {
+			continue
+		}
+		events = append(events, exporter.Event{
+			UID:      fmt.Sprintf("%v-%v@yxy-go", record.ID, departure.Unix()),
+			Summary:  "校车 " + record.Name,
+			Start:    departure,
+			Duration: busRideDuration,
+		})
+	}
+	return events
+}
+
+func parseDepartureTime(s string) (time.Time, error) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	var err error
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, strings.TrimSpace(s), loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,33 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/syncx"
+)
+
+// syncFetchFlight 缓存为空时的同步获取, 并发请求只触发一次
+var syncFetchFlight = syncx.NewSingleFlight()
+
+// syncFetchTimeout 同步获取的超时时间, 所有等待的请求共用这一次获取
+const syncFetchTimeout = 30 * time.Second
+
+// syncFetchContext 同步获取不跟随第一个请求取消, 避免该请求断开时其他等待的请求一起失败
+func syncFetchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), syncFetchTimeout)
+}
+
+// freshness 根据缓存更新时间计算数据年龄, 超过 maxAge 视为过期
+func freshness(updatedAt int64, maxAge time.Duration, now time.Time) (stale bool, ageSeconds int64) {
+	age := now.Sub(time.UnixMilli(updatedAt))
+	return age > maxAge, int64(age.Seconds())
+}
+
+// isDataNotReady 判断错误是否为缓存数据暂未就绪
+func isDataNotReady(err error) bool {
+	var e *xerr.ErrCode
+	return errors.As(err, &e) && e.Code() == xerr.ErrDataNotReady
+}
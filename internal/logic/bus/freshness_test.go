@@ -0,0 +1,30 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"yxy-go/pkg/xerr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreshness(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+
+	stale, age := freshness(now.Add(-90*time.Second).UnixMilli(), 5*time.Minute, now)
+	assert.False(t, stale)
+	assert.Equal(t, int64(90), age)
+
+	stale, age = freshness(now.Add(-6*time.Minute).UnixMilli(), 5*time.Minute, now)
+	assert.True(t, stale)
+	assert.Equal(t, int64(360), age)
+}
+
+func TestIsDataNotReady(t *testing.T) {
+	assert.True(t, isDataNotReady(xerr.WithCode(xerr.ErrDataNotReady, "empty")))
+	assert.False(t, isDataNotReady(xerr.WithCode(xerr.ErrUnknown, "redis")))
+	assert.False(t, isDataNotReady(errors.New("plain")))
+	assert.False(t, isDataNotReady(nil))
+}
@@ -3,21 +3,30 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 	"yxy-go/internal/consts"
 	"yxy-go/internal/manager/auth"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/types"
 	"yxy-go/internal/utils/yxyClient"
+	"yxy-go/pkg/xerr"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+const announcementFormatMarkdown = "markdown"
+
 type GetBusAnnouncementLogic struct {
 	logx.Logger
 	ctx         context.Context
 	svcCtx      *svc.ServiceContext
 	authManager *auth.BusAuthManager
+
+	onAnnouncementsChanged func([]AnnouncementChange)
 }
 
 func NewGetBusAnnouncementLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusAnnouncementLogic {
@@ -38,13 +47,97 @@ func (l *GetBusAnnouncementLogic) GetBusAnnouncement(req *types.GetBusAnnounceme
 	if pageSize > 10 || pageSize < 1 {
 		pageSize = 10
 	}
-	return l.getAnnouncementFromCache(page, pageSize)
+	query := func() (*types.GetBusAnnouncementResp, error) {
+		if req.Keyword != "" || req.Author != "" || req.From != "" || req.To != "" || req.Cursor != "" {
+			return l.searchAnnouncement(req)
+		}
+		return l.getAnnouncementFromCache(page, pageSize)
+	}
+	resp, err = query()
+	if isDataNotReady(err) {
+		if err = l.syncUpdateAnnouncement(); err != nil {
+			return nil, err
+		}
+		resp, err = query()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 只有指定 markdown 格式时才返回 Markdown
+	if req.Format != announcementFormatMarkdown {
+		for i := range resp.List {
+			resp.List[i].Markdown = ""
+		}
+	}
+	return resp, nil
+}
+
+// searchAnnouncement 通过搜索索引按关键字、作者和发布日期筛选公告, 使用游标分页
+func (l *GetBusAnnouncementLogic) searchAnnouncement(req *types.GetBusAnnouncementReq) (*types.GetBusAnnouncementResp, error) {
+	pageSize := req.PageSize
+	if pageSize > 50 || pageSize < 1 {
+		pageSize = 10
+	}
+	from, err := normalizeDate(req.From)
+	if err != nil {
+		return nil, xerr.WithCode(xerr.ErrParam, err.Error())
+	}
+	to, err := normalizeDate(req.To)
+	if err != nil {
+		return nil, xerr.WithCode(xerr.ErrParam, err.Error())
+	}
+
+	idx, err := l.getAnnouncementIndex()
+	if errors.Is(err, redis.Nil) {
+		return nil, xerr.WithCode(xerr.ErrDataNotReady, "bus announcement index not found")
+	}
+	if err != nil {
+		l.Logger.Errorf("获取校车公告搜索索引失败: %v", err)
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	list, total, next, err := idx.search(announcementQuery{
+		Keyword: req.Keyword,
+		Author:  req.Author,
+		From:    from,
+		To:      to,
+		Cursor:  req.Cursor,
+		Limit:   pageSize,
+	})
+	if err != nil {
+		return nil, xerr.WithCode(xerr.ErrParam, err.Error())
+	}
+
+	updatedAt, err := l.getUpdatedAt()
+	if err != nil {
+		return nil, err
+	}
+	stale, age := freshness(updatedAt, l.svcCtx.Config.BusService.AnnouncementMaxAge, time.Now())
+	return &types.GetBusAnnouncementResp{
+		UpdatedAt:  time.UnixMilli(updatedAt).Format("2006-01-02 15:04:05"),
+		Stale:      stale,
+		AgeSeconds: age,
+		Total:      int64(total),
+		List:       list,
+		NextCursor: next,
+	}, nil
+}
+
+// normalizeDate 将 20060102 或 2006-01-02 格式的日期统一为 2006-01-02
+func normalizeDate(date string) (string, error) {
+	if date == "" {
+		return "", nil
+	}
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid date: %v", date)
 }
 
 // getAnnouncementFromCache 从缓存获取公告信息
 func (l *GetBusAnnouncementLogic) getAnnouncementFromCache(page, pageSize int) (*types.GetBusAnnouncementResp, error) {
 	cacheKey := "bus:announcement:data"
-	cacheUpdatedAtKey := "bus:announcement:updated_at"
 
 	// 计算分页的起始和结束索引
 	start := int64((page - 1) * pageSize)
@@ -72,25 +165,41 @@ func (l *GetBusAnnouncementLogic) getAnnouncementFromCache(page, pageSize int) (
 	}
 
 	// 获取更新时间
-	updatedAt, err := l.svcCtx.Rdb.Get(l.ctx, cacheUpdatedAtKey).Int64()
+	updatedAt, err := l.getUpdatedAt()
 	if err != nil {
 		return nil, err
 	}
+	stale, age := freshness(updatedAt, l.svcCtx.Config.BusService.AnnouncementMaxAge, time.Now())
 
 	return &types.GetBusAnnouncementResp{
-		UpdatedAt: time.UnixMilli(updatedAt).Format("2006-01-02 15:04:05"),
-		Total:     total,
-		List:      announcementList,
+		UpdatedAt:  time.UnixMilli(updatedAt).Format("2006-01-02 15:04:05"),
+		Stale:      stale,
+		AgeSeconds: age,
+		Total:      total,
+		List:       announcementList,
 	}, nil
 }
 
+// getUpdatedAt 获取公告缓存的更新时间
+func (l *GetBusAnnouncementLogic) getUpdatedAt() (int64, error) {
+	updatedAt, err := l.svcCtx.Rdb.Get(l.ctx, "bus:announcement:updated_at").Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, xerr.WithCode(xerr.ErrDataNotReady, "bus announcement updated_at not found")
+	}
+	if err != nil {
+		return 0, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	return updatedAt, nil
+}
+
 type fetchAnnouncementResp struct {
 	Result []struct {
-		Ctime   string `json:"ctime"`
-		Title   string `json:"title"`
-		Content string `json:"content"`
-		HTML    string `json:"html"`
-		Author  string `json:"author"`
+		ID      json.RawMessage `json:"id"` // 字符串或数字, 不存在时为空
+		Ctime   string          `json:"ctime"`
+		Title   string          `json:"title"`
+		Content string          `json:"content"`
+		HTML    string          `json:"html"`
+		Author  string          `json:"author"`
 	} `json:"results"`
 }
 
@@ -98,6 +207,7 @@ func (l *GetBusAnnouncementLogic) FetchAnnouncement(token string) (resp []types.
 	client := yxyClient.GetClient()
 	var fetchResp fetchAnnouncementResp
 	_, err = client.R().
+		SetContext(l.ctx).
 		SetQueryParams(map[string]string{
 			"page_size": "999",
 		}).
@@ -108,13 +218,35 @@ func (l *GetBusAnnouncementLogic) FetchAnnouncement(token string) (resp []types.
 		return nil, err
 	}
 	for _, item := range fetchResp.Result {
+		blocks := yxyClient.ParseHTMLBlocks(item.HTML)
+		announcementBlocks := make([]types.BusAnnouncementBlock, len(blocks))
+		for i, block := range blocks {
+			announcementBlocks[i] = types.BusAnnouncementBlock(block)
+		}
 		resp = append(resp, types.BusAnnouncement{
+			ID:          announcementID(rawID(item.ID), item.Ctime, item.Author, item.Title),
 			Title:       item.Title,
 			Author:      item.Author,
 			PublishedAt: item.Ctime,
 			Abstract:    item.Content,
 			Content:     yxyClient.ParseHTMLAnnouncement(item.HTML),
+			Blocks:      announcementBlocks,
+			Markdown:    yxyClient.BlocksToMarkdown(blocks),
 		})
 	}
 	return resp, nil
 }
+
+// rawID 易校园的公告 ID 可能是字符串或数字, 统一转为字符串
+func rawID(raw json.RawMessage) string {
+	id := strings.Trim(string(raw), `"`)
+	if id == "null" {
+		return ""
+	}
+	return id
+}
+
+// ParsePublishedAt 解析公告的发布时间
+func ParsePublishedAt(a types.BusAnnouncement) (time.Time, error) {
+	return parseDepartureTime(a.PublishedAt)
+}
@@ -0,0 +1,81 @@
+package bus
+
+import (
+	"context"
+	"strconv"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/jsonx"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetBusAnnouncementUpdatesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetBusAnnouncementUpdatesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusAnnouncementUpdatesLogic {
+	return &GetBusAnnouncementUpdatesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetBusAnnouncementUpdates 获取游标之后新发布或被编辑的公告, 按变更时间升序; 返回的游标用于下一次请求
+func (l *GetBusAnnouncementUpdatesLogic) GetBusAnnouncementUpdates(req *types.GetBusAnnouncementUpdatesReq) (resp *types.GetBusAnnouncementUpdatesResp, err error) {
+	limit := req.Limit
+	if limit > 50 || limit < 1 {
+		limit = 20
+	}
+	ids, err := l.svcCtx.Rdb.ZRangeByScoreWithScores(l.ctx, announcementChangesCacheKey, &redis.ZRangeBy{
+		Min:   "(" + strconv.FormatInt(req.Cursor, 10),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		l.Logger.Errorf("获取校车公告变更失败: %v", err)
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+
+	resp = &types.GetBusAnnouncementUpdatesResp{
+		Cursor: req.Cursor,
+		List:   make([]types.BusAnnouncementUpdate, 0, len(ids)),
+	}
+	if len(ids) == 0 {
+		return resp, nil
+	}
+	fields := make([]string, len(ids))
+	for i, id := range ids {
+		fields[i] = id.Member.(string)
+	}
+	raws, err := l.svcCtx.Rdb.HMGet(l.ctx, announcementSeenCacheKey, fields...).Result()
+	if err != nil {
+		l.Logger.Errorf("获取校车公告记录失败: %v", err)
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	for i, raw := range raws {
+		resp.Cursor = int64(ids[i].Score)
+		str, ok := raw.(string)
+		if !ok {
+			continue
+		}
+		var record announcementRecord
+		if err = jsonx.UnmarshalFromString(str, &record); err != nil {
+			l.Logger.Errorf("校车公告记录反序列化失败: %v", err)
+			continue
+		}
+		resp.List = append(resp.List, types.BusAnnouncementUpdate{
+			Type:         record.Type,
+			FirstSeenAt:  record.FirstSeenAt,
+			ChangedAt:    record.ChangedAt,
+			Announcement: record.Announcement,
+		})
+	}
+	return resp, nil
+}
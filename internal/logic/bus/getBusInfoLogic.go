@@ -3,6 +3,8 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 	"yxy-go/internal/consts"
@@ -12,14 +14,17 @@ import (
 	"yxy-go/internal/utils/yxyClient"
 	"yxy-go/pkg/xerr"
 
+	"github.com/go-resty/resty/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
 type GetBusInfoLogic struct {
 	logx.Logger
-	ctx         context.Context
-	svcCtx      *svc.ServiceContext
-	authManager *auth.BusAuthManager
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	authManager   *auth.BusAuthManager
+	onSeatsOpened func([]SeatOpening)
 }
 
 func NewGetBusInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusInfoLogic {
@@ -32,22 +37,53 @@ func NewGetBusInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBus
 }
 
 func (l *GetBusInfoLogic) GetBusInfo(req *types.GetBusInfoReq) (*types.GetBusInfoResp, error) {
-	if req.Search == "" {
-		// 全量获取
-		return l.getBusInfoFromCache(func(_ types.BusInfo) bool {
-			return true
-		})
+	// 全量获取, 有搜索词时在本地搜索
+	all := func(_ types.BusInfo) bool {
+		return true
+	}
+	busData, err := l.getBusInfoFromCache(all)
+	if isDataNotReady(err) {
+		if err = l.syncUpdateBusInfo(); err != nil {
+			return nil, err
+		}
+		busData, err = l.getBusInfoFromCache(all)
 	}
-	uid := l.svcCtx.Config.BusService.UID
-	resp, err := l.authManager.WithAuthToken(uid, func(token string) (any, error) {
-		return l.SearchBusInfo(token, req.Search)
-	})
 	if err != nil {
 		return nil, err
 	}
-	busData, ok := resp.(*types.GetBusInfoResp)
-	if !ok {
-		return nil, xerr.WithCode(xerr.ErrUnknown, "解析校车信息失败")
+	if req.Search != "" {
+		busData.List = searchBusInfo(busData.List, req.Search)
+	}
+	// 本地无结果时按配置回退到上游搜索
+	if req.Search != "" && len(busData.List) == 0 && l.svcCtx.Config.BusService.SearchUpstreamFallback {
+		resp, err := withServiceAccount(l.ctx, l.svcCtx, l.authManager, func(token string) (any, error) {
+			return l.SearchBusInfo(token, req.Search)
+		})
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		busData, ok = resp.(*types.GetBusInfoResp)
+		if !ok {
+			return nil, xerr.WithCode(xerr.ErrUnknown, "解析校车信息失败")
+		}
+	}
+
+	changedAt, err := l.getLastChangedAt()
+	if err != nil {
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	busData.LastChangedAt = changedAt
+	if req.ChangedSince > 0 {
+		// 客户端已是最新时无需再查询变更记录
+		if req.ChangedSince >= changedAt {
+			busData.List = make([]types.BusInfo, 0)
+			busData.Changes = make([]types.BusChangeEvent, 0)
+			return busData, nil
+		}
+		if err = l.applyChangedSince(busData, req.ChangedSince); err != nil {
+			return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+		}
 	}
 	return busData, nil
 }
@@ -56,7 +92,10 @@ func (l *GetBusInfoLogic) getBusInfoFromCache(filter func(types.BusInfo) bool) (
 	// 全量获取校车信息
 	busInfoListRaw, err := l.svcCtx.Rdb.LRange(l.ctx, "bus:info:data", 0, -1).Result()
 	if err != nil {
-		return nil, err
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	if len(busInfoListRaw) == 0 {
+		return nil, xerr.WithCode(xerr.ErrDataNotReady, "bus info cache is empty")
 	}
 	busInfoList := make([]types.BusInfo, 0)
 	for _, raw := range busInfoListRaw {
@@ -70,63 +109,35 @@ func (l *GetBusInfoLogic) getBusInfoFromCache(filter func(types.BusInfo) bool) (
 	}
 
 	// 获取更新时间
-	updatedAt, err := l.svcCtx.Rdb.Get(l.ctx, "bus:info:updated_at").Int64()
+	updatedAt, err := l.getUpdatedAt()
 	if err != nil {
 		return nil, err
 	}
+	stale, age := freshness(updatedAt, l.svcCtx.Config.BusService.InfoMaxAge, time.Now())
 	return &types.GetBusInfoResp{
-		UpdatedAt: time.UnixMilli(updatedAt).Format("2006-01-02 15:04:05"),
-		List:      busInfoList,
+		UpdatedAt:  time.UnixMilli(updatedAt).Format("2006-01-02 15:04:05"),
+		Stale:      stale,
+		AgeSeconds: age,
+		List:       busInfoList,
 	}, nil
 }
 
-// FetchAllBusInfo 获取全量校车信息
-func (l *GetBusInfoLogic) FetchAllBusInfo(token string) ([]types.BusInfo, error) {
-	busInfoListRaw, err := l.fetchBusList(token, "")
-	if err != nil {
-		l.Logger.Errorf("获取校车信息失败, http 请求失败")
-		return nil, err
+// getUpdatedAt 获取校车信息和时刻表的缓存更新时间
+func (l *GetBusInfoLogic) getUpdatedAt() (int64, error) {
+	updatedAt, err := l.svcCtx.Rdb.Get(l.ctx, "bus:info:updated_at").Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, xerr.WithCode(xerr.ErrDataNotReady, "bus info updated_at not found")
 	}
-	busInfoList := make([]types.BusInfo, len(busInfoListRaw.Results))
-	for i := range busInfoList {
-		// 填充字段
-		raw := busInfoListRaw.Results[i]
-		info := types.BusInfo{
-			ID:       raw.ID,
-			Name:     raw.Name,
-			Price:    raw.Price,
-			Stations: make([]string, len(raw.Station)),
-		}
-		for j, station := range raw.Station {
-			info.Stations[j] = station.Name
-		}
-		// 获取班次
-		busScheduleRespRaw, err := l.fetchBusSchedule(token, raw.ID)
-		if err != nil {
-			l.Logger.Errorf("获取校车时间失败, %v", err)
-			return nil, err
-		}
-		info.BusTime = make([]types.BusTime, 0, len(busScheduleRespRaw))
-
-		for _, busTime := range busScheduleRespRaw {
-			// 获取各个班次预约情况
-			ordered, remaining, departureDatetime, err := l.fetchBusReservation(token, info.ID, busTime.ID)
-			if err != nil {
-				l.Logger.Errorf("获取校车日期失败, %v", err)
-				continue
-			}
-			if ordered == 0 && remaining == 0 {
-				continue
-			}
-			info.BusTime = append(info.BusTime, types.BusTime{
-				DepartureTime: departureDatetime,
-				RemainSeats:   remaining,
-				OrderedSeats:  ordered,
-			})
-		}
-		busInfoList[i] = info
+	if err != nil {
+		return 0, xerr.WithCode(xerr.ErrUnknown, err.Error())
 	}
-	return busInfoList, nil
+	return updatedAt, nil
+}
+
+// FetchAllBusInfo 获取全量校车信息
+func (l *GetBusInfoLogic) FetchAllBusInfo(token string) ([]types.BusInfo, error) {
+	busInfoList, _, err := l.FetchAllBusData(token)
+	return busInfoList, err
 }
 
 func (l *GetBusInfoLogic) SearchBusInfo(token, search string) (*types.GetBusInfoResp, error) {
@@ -147,13 +158,18 @@ func (l *GetBusInfoLogic) SearchBusInfo(token, search string) (*types.GetBusInfo
 	})
 }
 
-// List:M -> Schedule:N -> Reservation: O(M*N)
+// List:M -> Schedule:N -> Reservation: O(M*N), 全量爬取见 crawler.go
 // fetchBusList 获取校车信息列表
 func (l *GetBusInfoLogic) fetchBusList(token, search string) (*FetchBusInfoYxyResp, error) {
 	var yxyResp FetchBusInfoYxyResp
 
+	if err := l.waitRateLimit(consts.GET_BUS_INFO_URL); err != nil {
+		return nil, err
+	}
 	client := yxyClient.GetClient()
-	_, err := client.R().
+	var errResp yxyClient.YxyBusErrorResp
+	r, err := client.R().
+		SetContext(l.ctx).
 		SetQueryParams(map[string]string{
 			"search":    search,
 			"page":      "1",
@@ -161,11 +177,17 @@ func (l *GetBusInfoLogic) fetchBusList(token, search string) (*FetchBusInfoYxyRe
 		}).
 		SetHeader("Authorization", token).
 		SetResult(&yxyResp).
+		SetError(&errResp).
 		Get(consts.GET_BUS_INFO_URL)
-
+	if err == nil {
+		err = busRespError(r, errResp)
+	} else {
+		err = xerr.WithCode(xerr.ErrHttpClient, err.Error())
+	}
+	observeCrawlRequest("list", err)
 	if err != nil {
 		l.Logger.Errorf("Error sending request to %s: %v\n", consts.GET_BUS_INFO_URL, err)
-		return nil, xerr.WithCode(xerr.ErrHttpClient, err.Error())
+		return nil, err
 	}
 	return &yxyResp, nil
 }
@@ -178,46 +200,91 @@ func (l *GetBusInfoLogic) fetchBusSchedule(token, busID string) ([]FetchBusSched
 	// url := fmt.Sprintf(consts.GET_BUS_TIME_URL, busID)
 	url := strings.Replace(consts.GET_BUS_TIME_URL, "{id}", busID, 1)
 
+	if err := l.waitRateLimit(url); err != nil {
+		return nil, err
+	}
 	client := yxyClient.GetClient()
 
-	_, err := client.R().
+	var errResp yxyClient.YxyBusErrorResp
+	r, err := client.R().
+		SetContext(l.ctx).
 		SetQueryParams(map[string]string{
 			"shuttle_type": "-10",
 		}).
 		SetHeader("Authorization", token).
 		SetResult(&yxyResp).
+		SetError(&errResp).
 		Get(url)
-
+	if err == nil {
+		err = busRespError(r, errResp)
+	} else {
+		err = xerr.WithCode(xerr.ErrHttpClient, err.Error())
+	}
+	observeCrawlRequest("schedule", err)
 	if err != nil {
 		l.Logger.Errorf("Error sending request to %s: %v\n", consts.GET_BUS_TIME_URL, err)
-		return nil, xerr.WithCode(xerr.ErrHttpClient, err.Error())
+		return nil, err
 	}
 
 	return yxyResp, nil
 }
 
-// fetchBusReservation 获取校车班次预约情况
-func (l *GetBusInfoLogic) fetchBusReservation(token, busID, busScheduleID string) (ordered, remaining int, departureDatetime string, err error) {
+// fetchBusReservation 获取校车班次各个日期的预约情况
+func (l *GetBusInfoLogic) fetchBusReservation(token, busID, busScheduleID string) ([]types.BusDateSeats, error) {
 	var yxyResp FetchBusReservationYxyResp
-	ordered, remaining = 0, 0
 	url := strings.Replace(consts.GET_BUS_DATE_URL, "{id}", busID, 1)
 
+	if err := l.waitRateLimit(url); err != nil {
+		return nil, err
+	}
 	client := yxyClient.GetClient()
 
-	_, err = client.R().
+	var errResp yxyClient.YxyBusErrorResp
+	r, err := client.R().
+		SetContext(l.ctx).
 		SetQueryParams(map[string]string{
 			"shuttle_bus_time": busScheduleID,
 		}).
 		SetHeader("Authorization", token).
 		SetResult(&yxyResp).
+		SetError(&errResp).
 		Get(url)
+	if err == nil {
+		err = busRespError(r, errResp)
+	} else {
+		err = xerr.WithCode(xerr.ErrHttpClient, err.Error())
+	}
+	observeCrawlRequest("reservation", err)
 	if err != nil {
 		l.Logger.Errorf("获取校车班次预约情况失败, Http请求失败  %s: %v", consts.GET_BUS_DATE_URL, err)
-		return 0, 0, "", xerr.WithCode(xerr.ErrHttpClient, err.Error())
+		return nil, err
 	}
-	result := yxyResp.Results
-	if len(result) == 0 {
-		return 0, 0, "", nil
+	dates := make([]types.BusDateSeats, 0, len(yxyResp.Results))
+	for _, result := range yxyResp.Results {
+		dates = append(dates, types.BusDateSeats{
+			Date:          dateOf(result.DepartureDatetime),
+			DepartureTime: result.DepartureDatetime,
+			RemainSeats:   result.RemainSeats,
+			OrderedSeats:  result.OrderedSeats,
+		})
+	}
+	return dates, nil
+}
+
+// busRespError 将校车接口的非 2xx 响应转换为错误, AUTH_FAIL 视为 token 失效
+func busRespError(r *resty.Response, errResp yxyClient.YxyBusErrorResp) error {
+	if r.IsSuccess() {
+		return nil
 	}
-	return result[0].OrderedSeats, result[0].RemainSeats, result[0].DepartureDatetime, nil
+	errCode := xerr.ErrUnknown
+	if errResp.Detail.Code == "AUTH_FAIL" {
+		errCode = xerr.ErrBusTokenInvalid
+	}
+	return xerr.WithCode(errCode, fmt.Sprintf("yxy response: %v", r))
+}
+
+// isBusTokenInvalid 判断错误是否为校车 token 失效
+func isBusTokenInvalid(err error) bool {
+	var e *xerr.ErrCode
+	return errors.As(err, &e) && e.Code() == xerr.ErrBusTokenInvalid
 }
@@ -0,0 +1,47 @@
+package bus
+
+import (
+	"context"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/jsonx"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetBusSeatHistoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetBusSeatHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusSeatHistoryLogic {
+	return &GetBusSeatHistoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetBusSeatHistoryLogic) GetBusSeatHistory(req *types.GetBusSeatHistoryReq) (resp *types.GetBusSeatHistoryResp, err error) {
+	raws, err := l.svcCtx.Rdb.ZRange(l.ctx, seatHistoryCacheKey(req.BusID, req.DepartureTime), 0, -1).Result()
+	if err != nil {
+		l.Logger.Errorf("获取班次余票历史失败: %v", err)
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	list := make([]types.BusSeatPoint, 0, len(raws))
+	for _, raw := range raws {
+		var point types.BusSeatPoint
+		if err = jsonx.UnmarshalFromString(raw, &point); err != nil {
+			return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+		}
+		list = append(list, point)
+	}
+	return &types.GetBusSeatHistoryResp{
+		BusID:         req.BusID,
+		DepartureTime: req.DepartureTime,
+		List:          list,
+	}, nil
+}
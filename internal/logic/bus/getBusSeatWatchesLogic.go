@@ -0,0 +1,41 @@
+package bus
+
+import (
+	"context"
+
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetBusSeatWatchesLogic struct {
+	logx.Logger
+	ctx          context.Context
+	svcCtx       *svc.ServiceContext
+	watchManager *busManager.SeatWatchManager
+}
+
+func NewGetBusSeatWatchesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusSeatWatchesLogic {
+	return &GetBusSeatWatchesLogic{
+		Logger:       logx.WithContext(ctx),
+		ctx:          ctx,
+		svcCtx:       svcCtx,
+		watchManager: busManager.NewSeatWatchManager(ctx, svcCtx),
+	}
+}
+
+func (l *GetBusSeatWatchesLogic) GetBusSeatWatches(req *types.GetBusSeatWatchesReq) (resp *types.GetBusSeatWatchesResp, err error) {
+	watches, err := l.watchManager.List(req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]types.BusSeatWatch, 0, len(watches))
+	for i := range watches {
+		list = append(list, toBusSeatWatch(&watches[i]))
+	}
+	return &types.GetBusSeatWatchesResp{
+		List: list,
+	}, nil
+}
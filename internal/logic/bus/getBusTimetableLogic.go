@@ -0,0 +1,152 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const busTimetableCacheKey = "bus:timetable:data"
+
+type GetBusTimetableLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetBusTimetableLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetBusTimetableLogic {
+	return &GetBusTimetableLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetBusTimetableLogic) GetBusTimetable(req *types.GetBusTimetableReq) (resp *types.GetBusTimetableResp, err error) {
+	infoLogic := NewGetBusInfoLogic(l.ctx, l.svcCtx)
+	raw, err := l.svcCtx.Rdb.Get(l.ctx, busTimetableCacheKey).Result()
+	if errors.Is(err, redis.Nil) {
+		if err = infoLogic.syncUpdateBusInfo(); err != nil {
+			return nil, err
+		}
+		raw, err = l.svcCtx.Rdb.Get(l.ctx, busTimetableCacheKey).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil, xerr.WithCode(xerr.ErrDataNotReady, "bus timetable cache is empty")
+		}
+	}
+	if err != nil {
+		l.Logger.Errorf("获取校车时刻表缓存失败: %v", err)
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	var list []types.BusTimetable
+	if err = json.Unmarshal([]byte(raw), &list); err != nil {
+		return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+	}
+	updatedAt, err := infoLogic.getUpdatedAt()
+	if err != nil {
+		return nil, err
+	}
+	stale, age := freshness(updatedAt, l.svcCtx.Config.BusService.InfoMaxAge, time.Now())
+	return &types.GetBusTimetableResp{
+		UpdatedAt:  time.UnixMilli(updatedAt).Format("2006-01-02 15:04:05"),
+		Stale:      stale,
+		AgeSeconds: age,
+		List:       filterTimetable(list, req),
+	}, nil
+}
+
+// filterTimetable 按起终点站、日期和发车时间段过滤时刻表, 过滤后没有班次的线路不返回
+func filterTimetable(list []types.BusTimetable, req *types.GetBusTimetableReq) []types.BusTimetable {
+	date := strings.ReplaceAll(req.Date, "-", "")
+	result := make([]types.BusTimetable, 0, len(list))
+	for _, timetable := range list {
+		if !passesThrough(timetable.Stations, req.From, req.To) {
+			continue
+		}
+		schedules := make([]types.BusSchedule, 0, len(timetable.Schedules))
+		for _, schedule := range timetable.Schedules {
+			if req.StartTime != "" && schedule.DepartureTime < req.StartTime {
+				continue
+			}
+			if req.EndTime != "" && schedule.DepartureTime > req.EndTime {
+				continue
+			}
+			if date != "" {
+				dates := make([]types.BusDateSeats, 0, 1)
+				for _, d := range schedule.Dates {
+					if strings.ReplaceAll(d.Date, "-", "") == date {
+						dates = append(dates, d)
+					}
+				}
+				if len(dates) == 0 {
+					continue
+				}
+				schedule.Dates = dates
+			}
+			schedules = append(schedules, schedule)
+		}
+		if len(schedules) == 0 {
+			continue
+		}
+		timetable.Schedules = schedules
+		result = append(result, timetable)
+	}
+	return result
+}
+
+// passesThrough 判断线路是否先经过 from 站再经过 to 站, 站名模糊匹配, 为空时不限制; 终点站不能作为上车站
+func passesThrough(stations []types.BusStation, from, to string) bool {
+	fromSeq, toSeq, lastSeq := -1, -1, -1
+	for _, station := range stations {
+		lastSeq = max(lastSeq, station.Seq)
+		if from != "" && fromSeq < 0 && strings.Contains(station.Name, from) {
+			fromSeq = station.Seq
+		}
+		if to != "" && strings.Contains(station.Name, to) {
+			toSeq = station.Seq
+		}
+	}
+	switch {
+	case from != "" && fromSeq < 0, to != "" && toSeq < 0:
+		return false
+	case from != "" && to != "":
+		return fromSeq < toSeq
+	case from != "":
+		return fromSeq < lastSeq
+	default:
+		return true
+	}
+}
+
+// clockOf 将发车时间统一为 "15:04" 格式
+func clockOf(s string) string {
+	if t, err := parseDepartureTime(s); err == nil {
+		return t.Format("15:04")
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t.Format("15:04")
+		}
+	}
+	return s
+}
+
+// dateOf 取发车时间的日期部分
+func dateOf(s string) string {
+	if t, err := parseDepartureTime(s); err == nil {
+		return t.Format("2006-01-02")
+	}
+	if len(s) >= 10 {
+		return s[:10]
+	}
+	return s
+}
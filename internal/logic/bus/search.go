@@ -0,0 +1,138 @@
+package bus
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"yxy-go/internal/types"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// 匹配得分, 线路名命中时额外加分
+const (
+	scoreExact     = 100
+	scorePrefix    = 80
+	scoreContains  = 60
+	scorePinyin    = 50
+	scoreInitials  = 40
+	scoreFuzzy     = 20
+	scoreRouteName = 10
+)
+
+// searchKey 预处理后的待匹配文本
+type searchKey struct {
+	text     string
+	pinyin   string
+	initials string
+}
+
+// searchKeys 缓存站名、线路名的拼音, 避免每次搜索重复转换
+var searchKeys sync.Map
+
+func searchKeyOf(s string) searchKey {
+	if key, ok := searchKeys.Load(s); ok {
+		return key.(searchKey)
+	}
+	key := searchKey{
+		text:     normalizeSearch(s),
+		pinyin:   toPinyin(s, pinyin.Normal),
+		initials: toPinyin(s, pinyin.FirstLetter),
+	}
+	searchKeys.Store(s, key)
+	return key
+}
+
+// toPinyin 将汉字转为拼音, 非汉字字符原样保留
+func toPinyin(s string, style int) string {
+	args := pinyin.NewArgs()
+	args.Style = style
+	args.Fallback = func(r rune, _ pinyin.Args) []string {
+		return []string{string(r)}
+	}
+	return normalizeSearch(strings.Join(pinyin.LazyPinyin(s, args), ""))
+}
+
+// normalizeSearch 转小写并去除空白和标点
+func normalizeSearch(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// matchScore 计算查询词与文本的匹配得分, 不匹配时返回 0
+func matchScore(key searchKey, query string) int {
+	switch {
+	case key.text == query:
+		return scoreExact
+	case strings.HasPrefix(key.text, query):
+		return scorePrefix
+	case strings.Contains(key.text, query):
+		return scoreContains
+	case strings.Contains(key.pinyin, query):
+		return scorePinyin
+	case strings.Contains(key.initials, query):
+		return scoreInitials
+	case isSubsequence(query, key.text), isSubsequence(query, key.pinyin):
+		return scoreFuzzy
+	default:
+		return 0
+	}
+}
+
+// isSubsequence 判断 query 的字符是否按顺序出现在 s 中
+func isSubsequence(query, s string) bool {
+	q := []rune(query)
+	if len(q) == 0 {
+		return false
+	}
+	i := 0
+	for _, r := range s {
+		if r == q[i] {
+			i++
+			if i == len(q) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchBusInfo 在本地按线路名、站名及其拼音、拼音首字母搜索校车信息, 结果按匹配度排序
+func searchBusInfo(list []types.BusInfo, search string) []types.BusInfo {
+	query := normalizeSearch(search)
+	if query == "" {
+		return list
+	}
+
+	type scored struct {
+		info  types.BusInfo
+		score int
+	}
+	var matched []scored
+	for _, info := range list {
+		score := 0
+		if s := matchScore(searchKeyOf(info.Name), query); s > 0 {
+			score = s + scoreRouteName
+		}
+		for _, station := range info.Stations {
+			score = max(score, matchScore(searchKeyOf(station), query))
+		}
+		if score > 0 {
+			matched = append(matched, scored{info: info, score: score})
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].score > matched[j].score
+	})
+
+	result := make([]types.BusInfo, len(matched))
+	for i, m := range matched {
+		result[i] = m.info
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package bus
+
+import (
+	"testing"
+
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchBusInfo(t *testing.T) {
+	list := []types.BusInfo{
+		{ID: "1", Name: "朝晖-屏峰", Stations: []string{"朝晖校区", "屏峰校区"}},
+		{ID: "2", Name: "屏峰-莫干山", Stations: []string{"屏峰校区", "莫干山校区"}},
+		{ID: "3", Name: "朝晖-莫干山", Stations: []string{"朝晖校区", "德胜路", "莫干山校区"}},
+	}
+	ids := func(list []types.BusInfo) []string {
+		result := make([]string, len(list))
+		for i, info := range list {
+			result[i] = info.ID
+		}
+		return result
+	}
+
+	// 线路名前缀命中排在站名命中之前
+	assert.Equal(t, []string{"2", "1"}, ids(searchBusInfo(list, "屏峰")))
+	assert.Equal(t, []string{"3"}, ids(searchBusInfo(list, "deshenglu")))
+	assert.Equal(t, []string{"3"}, ids(searchBusInfo(list, "DSL")))
+	assert.Equal(t, []string{"2", "3"}, ids(searchBusInfo(list, "mgs")))
+	assert.Equal(t, []string{"3"}, ids(searchBusInfo(list, "朝莫")))
+	assert.Empty(t, searchBusInfo(list, "小和山"))
+}
@@ -0,0 +1,38 @@
+package bus
+
+import (
+	"testing"
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffOpenedSeats(t *testing.T) {
+	prev := []types.BusInfo{{
+		ID:   "1",
+		Name: "朝晖-屏峰",
+		BusTime: []types.BusTime{
+			{DepartureTime: "2025-03-03 07:30:00", RemainSeats: 0},
+			{DepartureTime: "2025-03-03 17:30:00", RemainSeats: 0},
+			{DepartureTime: "2025-03-03 12:00:00", RemainSeats: 3},
+		},
+	}}
+	curr := []types.BusInfo{{
+		ID:   "1",
+		Name: "朝晖-屏峰",
+		BusTime: []types.BusTime{
+			{DepartureTime: "2025-03-03 07:30:00", RemainSeats: 0},
+			{DepartureTime: "2025-03-03 17:30:00", RemainSeats: 2},
+			{DepartureTime: "2025-03-03 12:00:00", RemainSeats: 5},
+			{DepartureTime: "2025-03-04 17:30:00", RemainSeats: 40},
+		},
+	}}
+
+	assert.Equal(t, []SeatOpening{{
+		BusID:         "1",
+		Name:          "朝晖-屏峰",
+		DepartureTime: "2025-03-03 17:30:00",
+		RemainSeats:   2,
+	}}, DiffOpenedSeats(prev, curr))
+	assert.Empty(t, DiffOpenedSeats(nil, curr))
+}
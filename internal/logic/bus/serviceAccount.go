@@ -0,0 +1,20 @@
+package bus
+
+import (
+	"context"
+	"yxy-go/internal/manager/auth"
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+)
+
+// withServiceAccount 从服务账号池中选取账号执行请求, 并记录请求结果用于健康检查
+func withServiceAccount(ctx context.Context, svcCtx *svc.ServiceContext, authManager *auth.BusAuthManager, fn func(token string) (any, error)) (any, error) {
+	pool := busManager.NewUIDPoolManager(ctx, svcCtx)
+	uid, err := pool.Acquire()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := authManager.WithAuthToken(uid, fn)
+	pool.Report(uid, err)
+	return resp, err
+}
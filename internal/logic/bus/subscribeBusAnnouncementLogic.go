@@ -0,0 +1,34 @@
+package bus
+
+import (
+	"context"
+
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SubscribeBusAnnouncementLogic struct {
+	logx.Logger
+	ctx                 context.Context
+	svcCtx              *svc.ServiceContext
+	subscriptionManager *busManager.AnnouncementSubscriptionManager
+}
+
+func NewSubscribeBusAnnouncementLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SubscribeBusAnnouncementLogic {
+	return &SubscribeBusAnnouncementLogic{
+		Logger:              logx.WithContext(ctx),
+		ctx:                 ctx,
+		svcCtx:              svcCtx,
+		subscriptionManager: busManager.NewAnnouncementSubscriptionManager(ctx, svcCtx),
+	}
+}
+
+func (l *SubscribeBusAnnouncementLogic) SubscribeBusAnnouncement(req *types.SubscribeBusAnnouncementReq) (resp *types.SubscribeBusAnnouncementResp, err error) {
+	if err = l.subscriptionManager.Subscribe(req.Uid); err != nil {
+		return nil, err
+	}
+	return &types.SubscribeBusAnnouncementResp{}, nil
+}
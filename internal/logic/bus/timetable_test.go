@@ -0,0 +1,82 @@
+package bus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"yxy-go/internal/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterTimetable(t *testing.T) {
+	list := []types.BusTimetable{
+		{
+			RouteID: "1",
+			Stations: []types.BusStation{
+				{Name: "朝晖校区", Seq: 1},
+				{Name: "屏峰校区", Seq: 2},
+			},
+			Schedules: []types.BusSchedule{
+				{ID: "a", DepartureTime: "07:30", Dates: []types.BusDateSeats{{Date: "2024-09-02"}, {Date: "2024-09-03"}}},
+				{ID: "b", DepartureTime: "17:30", Dates: []types.BusDateSeats{{Date: "2024-09-02"}}},
+			},
+		},
+		{
+			RouteID: "2",
+			Stations: []types.BusStation{
+				{Name: "屏峰校区", Seq: 1},
+				{Name: "朝晖校区", Seq: 2},
+			},
+			Schedules: []types.BusSchedule{
+				{ID: "c", DepartureTime: "08:00", Dates: []types.BusDateSeats{{Date: "2024-09-03"}}},
+			},
+		},
+	}
+
+	got := filterTimetable(list, &types.GetBusTimetableReq{From: "朝晖", To: "屏峰"})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "1", got[0].RouteID)
+
+	got = filterTimetable(list, &types.GetBusTimetableReq{Date: "20240903", EndTime: "12:00"})
+	assert.Len(t, got, 2)
+	assert.Equal(t, "a", got[0].Schedules[0].ID)
+	assert.Len(t, got[0].Schedules[0].Dates, 1)
+	assert.Equal(t, "c", got[1].Schedules[0].ID)
+
+	got = filterTimetable(list, &types.GetBusTimetableReq{From: "屏峰", StartTime: "09:00"})
+	assert.Empty(t, got)
+}
+
+func TestClockAndDateOf(t *testing.T) {
+	assert.Equal(t, "07:30", clockOf("07:30:00"))
+	assert.Equal(t, "07:30", clockOf("2024-09-02 07:30:00"))
+	assert.Equal(t, "2024-09-02", dateOf("2024-09-02 07:30:00"))
+}
+
+func TestBuildRoute(t *testing.T) {
+	var raw busRouteYxy
+	assert.NoError(t, json.Unmarshal([]byte(`{"id":"1","shuttle_name":"朝晖-屏峰","go_stations_json":[
+		{"id":"s2","station_name":"屏峰校区","station_seq":2},
+		{"id":"s1","station_name":"朝晖校区","station_seq":1}]}`), &raw))
+	schedules := []FetchBusScheduleYxyResp{
+		{ID: "a", DepartureTime: "07:30:00"},
+		{ID: "b", DepartureTime: "17:30:00"},
+	}
+	results := []scheduleDates{
+		{scheduleTask: scheduleTask{schedule: 1, scheduleID: "b"}, dates: []types.BusDateSeats{
+			{Date: "2024-09-02", DepartureTime: "2024-09-02 17:30:00"},
+		}},
+		{scheduleTask: scheduleTask{schedule: 0, scheduleID: "a"}, dates: []types.BusDateSeats{
+			{Date: "2024-09-02", DepartureTime: "2024-09-02 07:30:00", RemainSeats: 3},
+		}},
+	}
+
+	info, timetable := buildRoute(raw, schedules, results)
+	assert.Equal(t, "朝晖校区→屏峰校区", timetable.Direction)
+	assert.Equal(t, []string{"a", "b"}, []string{timetable.Schedules[0].ID, timetable.Schedules[1].ID})
+	assert.Equal(t, "07:30", timetable.Schedules[0].DepartureTime)
+	// 无人预约且无余票的班次不出现在校车信息中
+	assert.Len(t, info.BusTime, 1)
+	assert.Equal(t, 3, info.BusTime[0].RemainSeats)
+}
@@ -0,0 +1,34 @@
+package bus
+
+import (
+	"context"
+
+	busManager "yxy-go/internal/manager/bus"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UnsubscribeBusAnnouncementLogic struct {
+	logx.Logger
+	ctx                 context.Context
+	svcCtx              *svc.ServiceContext
+	subscriptionManager *busManager.AnnouncementSubscriptionManager
+}
+
+func NewUnsubscribeBusAnnouncementLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UnsubscribeBusAnnouncementLogic {
+	return &UnsubscribeBusAnnouncementLogic{
+		Logger:              logx.WithContext(ctx),
+		ctx:                 ctx,
+		svcCtx:              svcCtx,
+		subscriptionManager: busManager.NewAnnouncementSubscriptionManager(ctx, svcCtx),
+	}
+}
+
+func (l *UnsubscribeBusAnnouncementLogic) UnsubscribeBusAnnouncement(req *types.UnsubscribeBusAnnouncementReq) (resp *types.UnsubscribeBusAnnouncementResp, err error) {
+	if err = l.subscriptionManager.Unsubscribe(req.Uid); err != nil {
+		return nil, err
+	}
+	return &types.UnsubscribeBusAnnouncementResp{}, nil
+}
@@ -1,39 +1,108 @@
 package bus
 
 import (
+	"fmt"
 	"time"
 	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/zeromicro/go-zero/core/jsonx"
 )
 
-// UpdateAnnouncement 获取校车公告信息并重试
-func (l *GetBusAnnouncementLogic) UpdateAnnouncement() {
+// fetchAnnouncementData 使用服务账号池获取一次全部校车公告
+func (l *GetBusAnnouncementLogic) fetchAnnouncementData() ([]types.BusAnnouncement, error) {
+	resp, err := withServiceAccount(l.ctx, l.svcCtx, l.authManager, func(token string) (any, error) {
+		return l.FetchAnnouncement(token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	announcementData, ok := resp.([]types.BusAnnouncement)
+	if !ok {
+		return nil, xerr.WithCode(xerr.ErrUnknown, "解析校车公告信息失败")
+	}
+	return announcementData, nil
+}
+
+// UpdateAnnouncement 获取校车公告信息并重试, 重试次数用尽时返回错误
+func (l *GetBusAnnouncementLogic) UpdateAnnouncement() error {
 	maxRetries := l.svcCtx.Config.BusService.MaxRetries
-	uid := l.svcCtx.Config.BusService.UID
 	retries := 0
 	var announcementData []types.BusAnnouncement
 	for ; retries < maxRetries; retries++ {
-		resp, err := l.authManager.WithAuthToken(uid, func(token string) (any, error) {
-			return l.FetchAnnouncement(token)
-		})
-		_announcementData, ok := resp.([]types.BusAnnouncement)
-		if err == nil && ok {
+		var err error
+		if announcementData, err = l.fetchAnnouncementData(); err == nil {
 			l.Logger.Info("成功获取校车公告信息")
-			announcementData = _announcementData
 			break
 		}
 		l.Logger.Errorf("获取校车公告信息失败, 重试中... (重试次数 %d/%d): %v", retries+1, maxRetries, err)
-		time.Sleep(time.Second * 5)
+		select {
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		case <-time.After(time.Second * 5):
+		}
 	}
 	if retries == maxRetries {
 		l.Logger.Errorf("获取校车公告信息失败! (总重试次数: %d)", maxRetries)
-		return
+		return fmt.Errorf("获取校车公告信息失败, 已重试 %d 次", maxRetries)
 	}
-	if err := l.refreshAnnouncementCache(announcementData); err != nil {
+	if err := l.storeAnnouncements(announcementData); err != nil {
 		l.Logger.Errorf("刷新校车公告信息缓存失败: %v", err)
+		return err
 	}
+	return nil
+}
+
+// syncUpdateAnnouncement 缓存为空时同步获取一次校车公告, 失败时返回数据未就绪;
+// 只刷新缓存和索引, 变更由定时任务记录并推送, 否则这里记下的公告不会再被推送
+func (l *GetBusAnnouncementLogic) syncUpdateAnnouncement() error {
+	_, err := syncFetchFlight.Do("bus:announcement", func() (any, error) {
+		l.Logger.Info("校车公告缓存为空, 同步获取校车公告")
+		ctx, cancel := syncFetchContext(l.ctx)
+		defer cancel()
+		logic := NewGetBusAnnouncementLogic(ctx, l.svcCtx)
+		announcementData, err := logic.fetchAnnouncementData()
+		if err != nil {
+			return nil, err
+		}
+		return nil, logic.cacheAnnouncements(announcementData)
+	})
+	if err != nil {
+		return xerr.WithCode(xerr.ErrDataNotReady, err.Error())
+	}
+	return nil
+}
+
+// cacheAnnouncements 刷新公告缓存和搜索索引
+func (l *GetBusAnnouncementLogic) cacheAnnouncements(announcementData []types.BusAnnouncement) error {
+	if err := l.refreshAnnouncementCache(announcementData); err != nil {
+		return err
+	}
+	if err := l.rebuildAnnouncementIndex(announcementData); err != nil {
+		l.Logger.Errorf("重建校车公告搜索索引失败: %v", err)
+	}
+	return nil
+}
+
+// storeAnnouncements 刷新公告缓存和搜索索引, 记录变更并通知订阅用户
+func (l *GetBusAnnouncementLogic) storeAnnouncements(announcementData []types.BusAnnouncement) error {
+	if err := l.cacheAnnouncements(announcementData); err != nil {
+		return err
+	}
+	changes, err := l.recordAnnouncementChanges(announcementData, time.Now().UnixMilli())
+	if err != nil {
+		return fmt.Errorf("保存校车公告变更失败: %w", err)
+	}
+	if len(changes) > 0 && l.onAnnouncementsChanged != nil {
+		l.onAnnouncementsChanged(changes)
+	}
+	return nil
+}
+
+// OnAnnouncementsChanged 设置有新发布或被编辑的公告时的回调
+func (l *GetBusAnnouncementLogic) OnAnnouncementsChanged(fn func([]AnnouncementChange)) {
+	l.onAnnouncementsChanged = fn
 }
 
 // refreshAnnouncementCache 刷新缓存, 采用RPush临时key再Rename的方式保证原子性
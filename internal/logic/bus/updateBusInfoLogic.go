@@ -1,6 +1,7 @@
 package bus
 
 import (
+	"fmt"
 	"time"
 	"yxy-go/internal/types"
 	"yxy-go/pkg/xerr"
@@ -10,17 +11,19 @@ import (
 )
 
 type FetchBusInfoYxyResp struct {
-	Count   int `json:"count"`
-	Results []struct {
-		ID      string `json:"id"`
-		Name    string `json:"shuttle_name"`
-		Price   int    `json:"price"`
-		Station []struct {
-			ID    string `json:"id"`
-			Name  string `json:"station_name"`
-			Order int    `json:"station_seq"`
-		} `json:"go_stations_json"`
-	} `json:"results"`
+	Count   int           `json:"count"`
+	Results []busRouteYxy `json:"results"`
+}
+
+type busRouteYxy struct {
+	ID      string `json:"id"`
+	Name    string `json:"shuttle_name"`
+	Price   int    `json:"price"`
+	Station []struct {
+		ID    string `json:"id"`
+		Name  string `json:"station_name"`
+		Order int    `json:"station_seq"`
+	} `json:"go_stations_json"`
 }
 
 type FetchBusScheduleYxyResp struct {
@@ -32,7 +35,7 @@ type FetchBusScheduleYxyResp struct {
 }
 
 type FetchBusReservationYxyResp struct {
-	// 这里看似是一个列表但是他只会返回一个...
+	// 每个可预约日期一条记录, 按日期升序
 	Results []struct {
 		OrderedSeats      int    `json:"order_cnt"`
 		RemainSeats       int    `json:"remaining_seats"`
@@ -40,32 +43,150 @@ type FetchBusReservationYxyResp struct {
 	} `json:"results"`
 }
 
-// UpdateBusInfo 获取校车信息并重试
-func (l *GetBusInfoLogic) UpdateBusInfo() {
+type busFetchResult struct {
+	info       []types.BusInfo
+	timetables []types.BusTimetable
+}
+
+// fetchBusData 使用服务账号池获取一次全量校车信息和时刻表
+func (l *GetBusInfoLogic) fetchBusData() (*busFetchResult, error) {
+	resp, err := withServiceAccount(l.ctx, l.svcCtx, l.authManager, func(token string) (any, error) {
+		info, timetables, err := l.FetchAllBusData(token)
+		if err != nil {
+			return nil, err
+		}
+		return &busFetchResult{info: info, timetables: timetables}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := resp.(*busFetchResult)
+	if !ok {
+		return nil, xerr.WithCode(xerr.ErrUnknown, "解析校车信息失败")
+	}
+	return result, nil
+}
+
+// UpdateBusInfo 获取校车信息并重试, 重试次数用尽时返回错误
+func (l *GetBusInfoLogic) UpdateBusInfo() error {
 	maxRetries := l.svcCtx.Config.BusService.MaxRetries
-	uid := l.svcCtx.Config.BusService.UID
 	retries := 0
-	var busData []types.BusInfo
+	var result *busFetchResult
 	for ; retries < maxRetries; retries++ {
-		resp, err := l.authManager.WithAuthToken(uid, func(token string) (any, error) {
-			return l.FetchAllBusInfo(token)
-		})
-		_busData, ok := resp.([]types.BusInfo)
-		if err == nil && ok {
+		var err error
+		if result, err = l.fetchBusData(); err == nil {
 			l.Logger.Info("成功获取校车信息")
-			busData = _busData
 			break
 		}
 		l.Logger.Errorf("获取校车信息失败, 重试中... (重试次数 %d/%d): %v", retries+1, maxRetries, err)
-		time.Sleep(time.Second * 5)
+		select {
+		case <-l.ctx.Done():
+			return l.ctx.Err()
+		case <-time.After(time.Second * 5):
+		}
 	}
 	if retries == maxRetries {
-		l.Logger.Errorf("获取校车信息失败! (总重试次数: %s)", maxRetries)
-		return
+		l.Logger.Errorf("获取校车信息失败! (总重试次数: %d)", maxRetries)
+		return fmt.Errorf("获取校车信息失败, 已重试 %d 次", maxRetries)
 	}
-	if err := l.refreshCache(busData); err != nil {
+	if err := l.storeBusData(result); err != nil {
 		l.Logger.Errorf("刷新校车信息缓存失败: %v", err)
+		return err
+	}
+	return nil
+}
+
+// syncUpdateBusInfo 缓存为空时同步获取一次校车信息, 失败时返回数据未就绪
+func (l *GetBusInfoLogic) syncUpdateBusInfo() error {
+	_, err := syncFetchFlight.Do("bus:info", func() (any, error) {
+		l.Logger.Info("校车信息缓存为空, 同步获取校车信息")
+		ctx, cancel := syncFetchContext(l.ctx)
+		defer cancel()
+		logic := NewGetBusInfoLogic(ctx, l.svcCtx)
+		result, err := logic.fetchBusData()
+		if err != nil {
+			return nil, err
+		}
+		return nil, logic.storeBusData(result)
+	})
+	if err != nil {
+		return xerr.WithCode(xerr.ErrDataNotReady, err.Error())
+	}
+	return nil
+}
+
+// storeBusData 与上一次的数据比较后刷新缓存, 记录变更并通知出现余票的班次
+func (l *GetBusInfoLogic) storeBusData(result *busFetchResult) error {
+	// 缓存被覆盖前与上一次的数据比较, 找出变更和出现余票的班次
+	now := time.Now().UnixMilli()
+	var (
+		changes  []types.BusChangeEvent
+		openings []SeatOpening
+	)
+	prev, err := l.getBusInfoFromCache(func(_ types.BusInfo) bool {
+		return true
+	})
+	if err != nil {
+		l.Logger.Infof("获取上一次的校车信息失败, 跳过变更比较: %v", err)
+	} else {
+		changes = DiffBusInfo(prev.List, result.info, now)
+		if l.onSeatsOpened != nil {
+			openings = DiffOpenedSeats(prev.List, result.info)
+		}
+	}
+	if err = l.refreshCache(result.info); err != nil {
+		return err
+	}
+	// 缓存替换后再更新变更时间, 客户端拿到新的变更时间时一定能读到新的数据
+	if err = l.recordChanges(changes, now); err != nil {
+		l.Logger.Errorf("保存校车信息变更记录失败: %v", err)
 	}
+	if err = l.refreshTimetableCache(result.timetables); err != nil {
+		l.Logger.Errorf("刷新校车时刻表缓存失败: %v", err)
+	}
+	if len(openings) > 0 {
+		l.onSeatsOpened(openings)
+	}
+	return nil
+}
+
+// SeatOpening 由无余票变为有余票的班次
+type SeatOpening struct {
+	BusID         string
+	Name          string
+	DepartureTime string
+	RemainSeats   int
+}
+
+// OnSeatsOpened 设置班次出现余票时的回调
+func (l *GetBusInfoLogic) OnSeatsOpened(fn func([]SeatOpening)) {
+	l.onSeatsOpened = fn
+}
+
+// DiffOpenedSeats 找出上一次余票为 0、本次余票大于 0 的班次, 上一次不存在的班次不视为出现余票
+func DiffOpenedSeats(prev, curr []types.BusInfo) []SeatOpening {
+	prevSeats := make(map[string]int)
+	for _, info := range prev {
+		for _, t := range info.BusTime {
+			prevSeats[info.ID+"|"+t.DepartureTime] = t.RemainSeats
+		}
+	}
+
+	var openings []SeatOpening
+	for _, info := range curr {
+		for _, t := range info.BusTime {
+			remain, ok := prevSeats[info.ID+"|"+t.DepartureTime]
+			if ok && remain == 0 && t.RemainSeats > 0 {
+				openings = append(openings, SeatOpening{
+					BusID:         info.ID,
+					Name:          info.Name,
+					DepartureTime: t.DepartureTime,
+					RemainSeats:   t.RemainSeats,
+				})
+			}
+		}
+	}
+	return openings
 }
 
 // refreshCache 刷新缓存, 采用RPush临时key再Rename的方式保证原子性
@@ -103,3 +224,15 @@ func (l *GetBusInfoLogic) refreshCache(busData []types.BusInfo) error {
 	l.svcCtx.Rdb.Persist(l.ctx, cacheKey)
 	return nil
 }
+
+// refreshTimetableCache 刷新时刻表缓存, 时刻表整体序列化为一个 key, 更新时间与校车信息共用
+func (l *GetBusInfoLogic) refreshTimetableCache(timetables []types.BusTimetable) error {
+	if len(timetables) == 0 {
+		return xerr.WithCode(xerr.ErrUnknown, "校车时刻表为空，未更新缓存")
+	}
+	data, err := jsonx.Marshal(timetables)
+	if err != nil {
+		return err
+	}
+	return l.svcCtx.Rdb.Set(l.ctx, busTimetableCacheKey, data, 0).Err()
+}
@@ -0,0 +1,91 @@
+package card
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeSpending(t *testing.T) {
+	windows := []mealWindow{
+		{Meal: "breakfast", Start: 6 * 60, End: 10 * 60},
+		{Meal: "lunch", Start: 10*60 + 30, End: 13*60 + 30},
+		{Meal: "dinner", Start: 16*60 + 30, End: 19*60 + 30},
+	}
+	at := func(s string) time.Time {
+		tm, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		return tm
+	}
+	transactions := []cardTransaction{
+		{"朝晖一食堂", decimal.RequireFromString("5.00"), at("2024-09-02 07:30")},
+		{"朝晖一食堂", decimal.RequireFromString("12.50"), at("2024-09-02 11:45")},
+		{"教育超市", decimal.RequireFromString("8.50"), at("2024-09-02 21:10")},
+		{"朝晖浴室", decimal.RequireFromString("4.00"), at("2024-09-09 20:00")},
+		{"屏峰二食堂", decimal.RequireFromString("120.00"), at("2024-10-01 17:00")},
+	}
+
+	resp := analyzeSpending(transactions, windows, 3)
+	assert.Equal(t, "150.00", resp.Total)
+	assert.Equal(t, 5, resp.Count)
+	assert.Equal(t, "30.00", resp.AverageTicket)
+	assert.Len(t, resp.Daily, 3)
+	assert.Equal(t, "2024-09-02", resp.Daily[0].Period)
+	assert.Equal(t, "26.00", resp.Daily[0].Total)
+	assert.Equal(t, []string{"2024-W36", "2024-W37", "2024-W40"}, []string{resp.Weekly[0].Period, resp.Weekly[1].Period, resp.Weekly[2].Period})
+	assert.Equal(t, "2024-10", resp.Monthly[1].Period)
+	assert.Equal(t, "屏峰二食堂", resp.Merchants[0].Address)
+	assert.Equal(t, "canteen", resp.Merchants[0].Category)
+
+	categories := map[string]string{}
+	for _, m := range resp.Merchants {
+		categories[m.Address] = m.Category
+	}
+	assert.Equal(t, "supermarket", categories["教育超市"])
+	assert.Equal(t, "shower", categories["朝晖浴室"])
+
+	meals := map[string]int{}
+	for _, m := range resp.Meals {
+		meals[m.Meal] = m.Count
+	}
+	assert.Equal(t, map[string]int{"breakfast": 1, "lunch": 1, "dinner": 1, "other": 2}, meals)
+
+	if assert.Len(t, resp.Anomalies, 1) {
+		assert.Equal(t, "120.00", resp.Anomalies[0].Money)
+	}
+}
+
+func TestSpendingOf(t *testing.T) {
+	spending, ok := spendingOf("", "", decimal.RequireFromString("-12.50"))
+	assert.True(t, ok)
+	assert.Equal(t, "12.50", spending.StringFixed(2))
+
+	// 与交易记录的分类一致: 按关键字判断, 消费记录的金额为正数时同样计入
+	spending, ok = spendingOf("消费", "", decimal.RequireFromString("8.00"))
+	assert.True(t, ok)
+	assert.Equal(t, "8.00", spending.StringFixed(2))
+
+	for _, c := range []struct{ rawType, amount string }{
+		{"", "100.00"}, {"充值", "-3.50"}, {"补助", "20.00"}, {"消费", "0"},
+	} {
+		_, ok = spendingOf(c.rawType, "", decimal.RequireFromString(c.amount))
+		assert.False(t, ok, c)
+	}
+}
+
+func TestMealOfOverlap(t *testing.T) {
+	windows := []mealWindow{
+		{Meal: "breakfast", Start: 6 * 60, End: 11 * 60},
+		{Meal: "lunch", Start: 10*60 + 30, End: 13*60 + 30},
+	}
+	at := time.Date(2024, 9, 2, 10, 45, 0, 0, time.Local)
+	assert.Equal(t, "breakfast", mealOf(at, windows))
+}
+
+func TestAnalyzeSpendingEmpty(t *testing.T) {
+	resp := analyzeSpending(nil, nil, 3)
+	assert.Equal(t, 0, resp.Count)
+	assert.NotNil(t, resp.Daily)
+	assert.NotNil(t, resp.Anomalies)
+}
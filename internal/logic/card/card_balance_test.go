@@ -0,0 +1,12 @@
+package card
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletNosOf(t *testing.T) {
+	assert.Equal(t, []string{"1"}, walletNosOf(nil))
+	assert.Equal(t, []string{"1", "2", "3"}, walletNosOf([]string{"3", "", "1", "2", "3"}))
+}
@@ -0,0 +1,56 @@
+package card
+
+import (
+	"testing"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryDates(t *testing.T) {
+	tests := []struct {
+		req     types.GetCardConsumptionRecordsReq
+		want    []string
+		errCode xerr.Code
+	}{
+		{types.GetCardConsumptionRecordsReq{QueryTime: "20240905"}, []string{"20240905"}, xerr.ErrSuccess},
+		{types.GetCardConsumptionRecordsReq{QueryTime: "20240905", From: "20240901", To: "20240930"}, []string{"20240905"}, xerr.ErrSuccess},
+		{types.GetCardConsumptionRecordsReq{From: "20240228", To: "20240302"}, []string{"20240228", "20240229", "20240301", "20240302"}, xerr.ErrSuccess},
+		{types.GetCardConsumptionRecordsReq{From: "20240901"}, []string{"20240901"}, xerr.ErrSuccess},
+		{types.GetCardConsumptionRecordsReq{From: "20240910", To: "20240901"}, nil, xerr.ErrParam},
+		{types.GetCardConsumptionRecordsReq{From: "20240101", To: "20241231"}, nil, xerr.ErrParam},
+		{types.GetCardConsumptionRecordsReq{QueryTime: "2024-09-05"}, nil, xerr.ErrParam},
+		{types.GetCardConsumptionRecordsReq{}, nil, xerr.ErrParam},
+	}
+
+	for _, tt := range tests {
+		got, err := parseQueryDates(&tt.req, 62)
+		if tt.errCode == xerr.ErrSuccess {
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			continue
+		}
+		if assert.Error(t, err) {
+			assert.Equal(t, tt.errCode, err.(*xerr.ErrCode).Code())
+		}
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	for money, want := range map[string]string{"12.5": "12.50", "-3.00": "-3.00", "+100": "100.00", " 8.80元": "8.80"} {
+		got, err := parseMoney(money)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got.StringFixed(2))
+	}
+}
+
+func TestClassifyTransaction(t *testing.T) {
+	assert.Equal(t, TransactionRecharge, classifyTransaction("", "支付宝充值", decimal.NewFromFloat(100)))
+	assert.Equal(t, TransactionRecharge, classifyTransaction("", "银行圈存", decimal.NewFromFloat(50)))
+	assert.Equal(t, TransactionSubsidy, classifyTransaction("", "餐费补助", decimal.NewFromFloat(30)))
+	assert.Equal(t, TransactionConsume, classifyTransaction("", "食堂消费", decimal.NewFromFloat(-12.5)))
+	assert.Equal(t, TransactionConsume, classifyTransaction("", "", decimal.NewFromFloat(-4)))
+	assert.Equal(t, TransactionOther, classifyTransaction("", "", decimal.NewFromFloat(4)))
+}
@@ -0,0 +1,52 @@
+package card
+
+import (
+	"context"
+	"fmt"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/internal/utils/exporter"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ExportCardConsumptionRecordsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportCardConsumptionRecordsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportCardConsumptionRecordsLogic {
+	return &ExportCardConsumptionRecordsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportCardConsumptionRecordsLogic) ExportCardConsumptionRecords(req *types.ExportCardConsumptionRecordsReq) (*exporter.File, error) {
+	resp, err := NewGetCardConsumptionRecordsLogic(l.ctx, l.svcCtx).GetCardConsumptionRecords(&types.GetCardConsumptionRecordsReq{
+		UID:       req.UID,
+		DeviceID:  req.DeviceID,
+		Token:     req.Token,
+		QueryTime: req.QueryTime,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	table := exporter.Table{Headers: []string{"消费时间", "消费地点", "金额"}}
+	for _, record := range resp.List {
+		table.Rows = append(table.Rows, []string{record.Time, record.Address, record.Money})
+	}
+
+	name := fmt.Sprintf("card_consumption_records_%v", req.UID)
+	if len(resp.Subtotals) > 0 {
+		// Subtotals 按日期倒序排列
+		name += fmt.Sprintf("_%v_%v", resp.Subtotals[len(resp.Subtotals)-1].Date, resp.Subtotals[0].Date)
+	}
+	return exporter.ExportTable(req.Format, name, table, resp)
+}
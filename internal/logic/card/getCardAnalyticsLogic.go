@@ -0,0 +1,309 @@
+package card
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/pkg/xerr"
+
+	"github.com/shopspring/decimal"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCardAnalyticsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCardAnalyticsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCardAnalyticsLogic {
+	return &GetCardAnalyticsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// cardTransaction 归一化后的消费记录
+type cardTransaction struct {
+	Address string
+	Amount  decimal.Decimal
+	Time    time.Time
+}
+
+// mealWindow 用餐时段, Start/End 为当天的分钟数
+type mealWindow struct {
+	Meal  string
+	Start int
+	End   int
+}
+
+// merchantCategories 根据消费地点关键字划分商户类别
+var merchantCategories = []struct {
+	Category string
+	Keywords []string
+}{
+	{"canteen", []string{"食堂", "餐厅", "饭堂", "餐饮"}},
+	{"supermarket", []string{"超市", "便利店", "商店"}},
+	{"shower", []string{"浴室", "淋浴", "洗浴"}},
+}
+
+func (l *GetCardAnalyticsLogic) GetCardAnalytics(req *types.GetCardAnalyticsReq) (resp *types.GetCardAnalyticsResp, err error) {
+	// 默认统计最近30天
+	from, to := req.From, req.To
+	if to == "" {
+		to = time.Now().Format("20060102")
+	}
+	if from == "" {
+		if toDate, err := time.Parse("20060102", to); err == nil {
+			from = toDate.AddDate(0, 0, -29).Format("20060102")
+		}
+	}
+
+	// 按固定顺序解析, 时段重叠时优先匹配靠前的餐段
+	mealTime := l.svcCtx.Config.Card.MealTime
+	var windows []mealWindow
+	for _, meal := range []struct{ name, window string }{
+		{"breakfast", mealTime.Breakfast},
+		{"lunch", mealTime.Lunch},
+		{"dinner", mealTime.Dinner},
+	} {
+		w, err := parseMealWindow(meal.name, meal.window)
+		if err != nil {
+			return nil, xerr.WithCode(xerr.ErrUnknown, err.Error())
+		}
+		windows = append(windows, w)
+	}
+
+	days, err := NewGetCardConsumptionRecordsLogic(l.ctx, l.svcCtx).queryRange(&types.GetCardConsumptionRecordsReq{
+		UID:      req.UID,
+		DeviceID: req.DeviceID,
+		Token:    req.Token,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []CardConsumptionYxyRecord
+	for _, day := range days {
+		rows = append(rows, day.records...)
+	}
+	resp = analyzeSpending(l.normalizeRecords(rows), windows, l.svcCtx.Config.Card.AnomalyMultiplier)
+	resp.From = from
+	resp.To = to
+	return resp, nil
+}
+
+// normalizeRecords 将交易记录中的金额和时间解析为数值, 只保留消费, 金额取正数
+func (l *GetCardAnalyticsLogic) normalizeRecords(records []CardConsumptionYxyRecord) []cardTransaction {
+	transactions := make([]cardTransaction, 0, len(records))
+	for _, record := range records {
+		amount, err := parseMoney(record.Money)
+		if err != nil {
+			l.Logger.Errorf("解析消费金额失败: %v", err)
+			continue
+		}
+		spending, ok := spendingOf(record.Type, record.FeeName, amount)
+		if !ok {
+			continue
+		}
+		t, err := parseRecordTime(record.Time)
+		if err != nil {
+			l.Logger.Errorf("解析消费时间失败: %v", err)
+			continue
+		}
+		transactions = append(transactions, cardTransaction{
+			Address: record.Address,
+			Amount:  spending,
+			Time:    t,
+		})
+	}
+	return transactions
+}
+
+// spendingOf 与交易记录使用相同的分类, 只有消费计入统计, 充值、补助等不计入
+func spendingOf(rawType, feeName string, amount decimal.Decimal) (decimal.Decimal, bool) {
+	if amount.IsZero() || classifyTransaction(rawType, feeName, amount) != TransactionConsume {
+		return decimal.Zero, false
+	}
+	return amount.Abs(), true
+}
+
+type spendingAgg struct {
+	total decimal.Decimal
+	count int
+}
+
+func (a *spendingAgg) add(amount decimal.Decimal) {
+	a.total = a.total.Add(amount)
+	a.count++
+}
+
+// analyzeSpending 按时间、商户、餐段统计消费, 并找出远高于平均消费的异常消费
+func analyzeSpending(transactions []cardTransaction, windows []mealWindow, anomalyMultiplier float64) *types.GetCardAnalyticsResp {
+	var total spendingAgg
+	daily := make(map[string]*spendingAgg)
+	weekly := make(map[string]*spendingAgg)
+	monthly := make(map[string]*spendingAgg)
+	merchants := make(map[string]*spendingAgg)
+	meals := make(map[string]*spendingAgg)
+
+	addTo := func(m map[string]*spendingAgg, key string, amount decimal.Decimal) {
+		if _, ok := m[key]; !ok {
+			m[key] = &spendingAgg{}
+		}
+		m[key].add(amount)
+	}
+
+	for _, t := range transactions {
+		total.add(t.Amount)
+		year, week := t.Time.ISOWeek()
+		addTo(daily, t.Time.Format("2006-01-02"), t.Amount)
+		addTo(weekly, fmt.Sprintf("%d-W%02d", year, week), t.Amount)
+		addTo(monthly, t.Time.Format("2006-01"), t.Amount)
+		addTo(merchants, t.Address, t.Amount)
+		addTo(meals, mealOf(t.Time, windows), t.Amount)
+	}
+
+	resp := &types.GetCardAnalyticsResp{
+		Total:     toAmount(total.total),
+		Count:     total.count,
+		Daily:     toSpendingPeriods(daily),
+		Weekly:    toSpendingPeriods(weekly),
+		Monthly:   toSpendingPeriods(monthly),
+		Merchants: make([]types.CardMerchantSpending, 0, len(merchants)),
+		Meals:     make([]types.CardMealSpending, 0, len(windows)+1),
+		Anomalies: make([]types.CardSpendingAnomaly, 0),
+	}
+	if total.count == 0 {
+		return resp
+	}
+	average := total.total.Div(decimal.NewFromInt(int64(total.count)))
+	resp.AverageTicket = toAmount(average)
+
+	addresses := make([]string, 0, len(merchants))
+	for address := range merchants {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return merchants[addresses[i]].total.GreaterThan(merchants[addresses[j]].total)
+	})
+	for _, address := range addresses {
+		agg := merchants[address]
+		resp.Merchants = append(resp.Merchants, types.CardMerchantSpending{
+			Address:  address,
+			Category: merchantCategory(address),
+			Total:    toAmount(agg.total),
+			Count:    agg.count,
+		})
+	}
+
+	mealOrder := []string{"breakfast", "lunch", "dinner", "other"}
+	for _, meal := range mealOrder {
+		agg, ok := meals[meal]
+		if !ok {
+			agg = &spendingAgg{}
+		}
+		resp.Meals = append(resp.Meals, types.CardMealSpending{
+			Meal:  meal,
+			Total: toAmount(agg.total),
+			Count: agg.count,
+		})
+	}
+
+	threshold := average.Mul(decimal.NewFromFloat(anomalyMultiplier))
+	for _, t := range transactions {
+		if t.Amount.GreaterThan(threshold) {
+			resp.Anomalies = append(resp.Anomalies, types.CardSpendingAnomaly{
+				Address: t.Address,
+				Money:   toAmount(t.Amount),
+				Time:    t.Time.Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+	sort.Slice(resp.Anomalies, func(i, j int) bool {
+		return resp.Anomalies[i].Time > resp.Anomalies[j].Time
+	})
+	return resp
+}
+
+func toSpendingPeriods(m map[string]*spendingAgg) []types.CardSpendingPeriod {
+	periods := make([]types.CardSpendingPeriod, 0, len(m))
+	for period, agg := range m {
+		periods = append(periods, types.CardSpendingPeriod{
+			Period: period,
+			Total:  toAmount(agg.total),
+			Count:  agg.count,
+		})
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Period < periods[j].Period
+	})
+	return periods
+}
+
+// toAmount 金额与交易记录一致, 使用保留两位小数的字符串
+func toAmount(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
+
+func merchantCategory(address string) string {
+	for _, c := range merchantCategories {
+		for _, keyword := range c.Keywords {
+			if strings.Contains(address, keyword) {
+				return c.Category
+			}
+		}
+	}
+	return "other"
+}
+
+func mealOf(t time.Time, windows []mealWindow) string {
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range windows {
+		if minute >= w.Start && minute < w.End {
+			return w.Meal
+		}
+	}
+	return "other"
+}
+
+// parseMealWindow 解析 "06:00-10:00" 格式的用餐时段
+func parseMealWindow(meal, window string) (mealWindow, error) {
+	parts := strings.Split(window, "-")
+	if len(parts) != 2 {
+		return mealWindow{}, fmt.Errorf("invalid meal time %v: %v", meal, window)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(parts[0]))
+	if err != nil {
+		return mealWindow{}, fmt.Errorf("invalid meal time %v: %v", meal, window)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(parts[1]))
+	if err != nil {
+		return mealWindow{}, fmt.Errorf("invalid meal time %v: %v", meal, window)
+	}
+	return mealWindow{
+		Meal:  meal,
+		Start: start.Hour()*60 + start.Minute(),
+		End:   end.Hour()*60 + end.Minute(),
+	}, nil
+}
+
+// parseRecordTime 解析消费记录中的时间
+func parseRecordTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range []string{"2006-01-02 15:04:05", "20060102150405", "2006/01/02 15:04:05"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, strings.TrimSpace(s), time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -2,28 +2,38 @@ package card
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"yxy-go/internal/consts"
+	"yxy-go/internal/manager/auth"
+	"yxy-go/internal/manager/device"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/types"
 	"yxy-go/internal/utils/yxyClient"
 	"yxy-go/pkg/xerr"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/mr"
 )
 
 type GetCardBalanceLogic struct {
 	logx.Logger
-	ctx    context.Context
-	svcCtx *svc.ServiceContext
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *device.DeviceProfileManager
+	authManager   *auth.CardAuthManager
 }
 
 func NewGetCardBalanceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCardBalanceLogic {
 	return &GetCardBalanceLogic{
-		Logger: logx.WithContext(ctx),
-		ctx:    ctx,
-		svcCtx: svcCtx,
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
+		authManager:   auth.NewCardAuthManager(ctx, svcCtx),
 	}
 }
 
@@ -35,16 +45,33 @@ type GetCardBalanceYxyResp struct {
 	Data       string `json:"data"`
 }
 
-func (l *GetCardBalanceLogic) GetCardBalance(req *types.GetCardBalanceReq) (resp *types.GetCardBalanceResp, err error) {
-	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParam(req.DeviceID)
-	yxyReq["ymId"] = req.UID
+// primaryWalletNo 主钱包编号
+const primaryWalletNo = "1"
+
+// walletsCacheTTL 钱包列表的缓存时间, 获取消费记录时会补充新出现的钱包, 所以可以缓存较长时间
+const walletsCacheTTL = 30 * 24 * time.Hour
+
+type cardWallet struct {
+	No   string
+	Name string
+}
+
+type walletBalance struct {
+	wallet types.CardWallet
+	raw    string
+}
+
+// fetchCardBalance 获取指定钱包的余额
+func (l *GetCardBalanceLogic) fetchCardBalance(uid, walletNo string, profile *yxyClient.DeviceProfile) (string, error) {
+	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(profile)
+	yxyReq["ymId"] = uid
 	yxyReq["schoolCode"] = consts.SCHOOL_CODE
-	yxyReq["walletNo"] = "1"
+	yxyReq["walletNo"] = walletNo
 
 	var yxyResp GetCardBalanceYxyResp
-	r, err := yxyClient.HttpSendPost(consts.GET_CARD_BALANCE_URL, yxyReq, yxyHeaders, &yxyResp)
+	r, err := yxyClient.HttpSendPostWithContext(l.ctx, consts.GET_CARD_BALANCE_URL, yxyReq, yxyHeaders, &yxyResp)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	if yxyResp.StatusCode != 0 {
@@ -58,10 +85,157 @@ func (l *GetCardBalanceLogic) GetCardBalance(req *types.GetCardBalanceReq) (resp
 		case "-1":
 			errCode = xerr.ErrNotBindCard
 		}
-		return nil, xerr.WithCode(errCode, fmt.Sprintf("yxy response: %v", r))
+		return "", xerr.WithCode(errCode, fmt.Sprintf("yxy response: %v", r))
+	}
+
+	return yxyResp.Data, nil
+}
+
+// getWallets 获取需要查询的钱包, 指定 walletNo 时只查询该钱包, 钱包名称来自配置
+func (l *GetCardBalanceLogic) getWallets(uid, walletNo string) []cardWallet {
+	names := make(map[string]string, len(l.svcCtx.Config.Card.Wallets))
+	for _, w := range l.svcCtx.Config.Card.Wallets {
+		names[w.WalletNo] = w.Name
+	}
+	if walletNo != "" {
+		return []cardWallet{{No: walletNo, Name: names[walletNo]}}
+	}
+
+	var wallets []cardWallet
+	for _, no := range l.getWalletNos(uid) {
+		wallets = append(wallets, cardWallet{No: no, Name: names[no]})
+	}
+	return wallets
+}
+
+func getWalletsCacheKey(uid string) string {
+	return "card:wallets:" + uid
+}
+
+// getWalletNos 易校园没有列出钱包的接口, 从最近 Card.WalletLookbackDays 天消费记录的 eWalletId 中获取用户使用过的钱包;
+// 按天的消费记录有缓存, 只有未缓存的日期才会请求易校园; 获取失败时只查询主钱包
+func (l *GetCardBalanceLogic) getWalletNos(uid string) []string {
+	key := getWalletsCacheKey(uid)
+	if nos, err := l.svcCtx.Rdb.SMembers(l.ctx, key).Result(); err == nil && len(nos) > 0 {
+		return walletNosOf(nos)
+	}
+
+	now := time.Now()
+	days, err := NewGetCardConsumptionRecordsLogic(l.ctx, l.svcCtx).queryRange(&types.GetCardConsumptionRecordsReq{
+		UID:  uid,
+		From: now.AddDate(0, 0, 1-l.svcCtx.Config.Card.WalletLookbackDays).Format("20060102"),
+		To:   now.Format("20060102"),
+	})
+	if err != nil {
+		l.Logger.Errorf("%s获取消费记录中的钱包失败, 只查询主钱包: %v", uid, err)
+		return []string{primaryWalletNo}
 	}
 
-	return &types.GetCardBalanceResp{
-		Balance: yxyResp.Data,
-	}, nil
+	var nos []string
+	for _, day := range days {
+		for _, row := range day.records {
+			nos = append(nos, row.EWalletId)
+		}
+	}
+	nos = walletNosOf(nos)
+	members := make([]any, 0, len(nos))
+	for _, no := range nos {
+		members = append(members, no)
+	}
+	pipe := l.svcCtx.Rdb.TxPipeline()
+	pipe.SAdd(l.ctx, key, members...)
+	pipe.Expire(l.ctx, key, walletsCacheTTL)
+	if _, err = pipe.Exec(l.ctx); err != nil {
+		l.Logger.Errorf("缓存钱包列表失败: %v", err)
+	}
+	return nos
+}
+
+// walletNosOf 去重并排序钱包编号, 主钱包始终在列表中
+func walletNosOf(nos []string) []string {
+	seen := map[string]struct{}{primaryWalletNo: {}}
+	result := []string{primaryWalletNo}
+	for _, no := range nos {
+		if _, ok := seen[no]; ok || no == "" {
+			continue
+		}
+		seen[no] = struct{}{}
+		result = append(result, no)
+	}
+	sort.Strings(result[1:])
+	return result
+}
+
+// fetchWalletBalances 并发查询各个钱包的余额;
+// 登录失效等错误直接返回以便重新登录, 其余错误的非主钱包在该钱包的 error 字段中返回
+func (l *GetCardBalanceLogic) fetchWalletBalances(uid string, wallets []cardWallet, profile *yxyClient.DeviceProfile) (*types.GetCardBalanceResp, error) {
+	balances, err := mr.MapReduce(func(source chan<- cardWallet) {
+		for _, w := range wallets {
+			source <- w
+		}
+	}, func(w cardWallet, writer mr.Writer[walletBalance], cancel func(error)) {
+		wallet := types.CardWallet{WalletNo: w.No, Name: w.Name}
+		balance, err := l.fetchCardBalance(uid, w.No, profile)
+		if err != nil {
+			var e *xerr.ErrCode
+			if len(wallets) > 1 && w.No != primaryWalletNo && errors.As(err, &e) && e.Code() == xerr.ErrUnknown {
+				l.Logger.Infof("%s查询钱包%s失败: %v", uid, w.No, err)
+				wallet.Error = e.Code().String()
+				writer.Write(walletBalance{wallet: wallet})
+				return
+			}
+			cancel(err)
+			return
+		}
+		amount, err := parseMoney(balance)
+		if err != nil {
+			cancel(xerr.WithCode(xerr.ErrUnknown, fmt.Sprintf("解析余额失败: %v", err)))
+			return
+		}
+		wallet.Balance = amount.StringFixed(2)
+		writer.Write(walletBalance{wallet: wallet, raw: balance})
+	}, func(pipe <-chan walletBalance, writer mr.Writer[[]walletBalance], cancel func(error)) {
+		balances := make([]walletBalance, 0, len(wallets))
+		for w := range pipe {
+			balances = append(balances, w)
+		}
+		writer.Write(balances)
+	}, mr.WithContext(l.ctx), mr.WithWorkers(l.svcCtx.Config.Card.QueryConcurrency))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].wallet.WalletNo < balances[j].wallet.WalletNo
+	})
+	// balance 字段保持原有含义: 主钱包余额, 指定钱包时为该钱包余额
+	resp := &types.GetCardBalanceResp{Wallets: make([]types.CardWallet, 0, len(balances))}
+	for _, b := range balances {
+		resp.Wallets = append(resp.Wallets, b.wallet)
+		if len(wallets) == 1 || b.wallet.WalletNo == primaryWalletNo {
+			resp.Balance = b.raw
+		}
+	}
+	return resp, nil
+}
+
+func (l *GetCardBalanceLogic) GetCardBalance(req *types.GetCardBalanceReq) (resp *types.GetCardBalanceResp, err error) {
+	profile, err := l.deviceManager.GetOrCreateProfile(req.UID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Token != "" {
+		if err = l.authManager.SaveAuthToken(req.UID, req.Token); err != nil {
+			return nil, err
+		}
+	}
+
+	wallets := l.getWallets(req.UID, req.WalletNo)
+	result, err := l.authManager.WithAuthToken(req.UID, func(_ string) (any, error) {
+		return l.fetchWalletBalances(req.UID, wallets, profile)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*types.GetCardBalanceResp), nil
 }
@@ -2,70 +2,88 @@ package card
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"yxy-go/internal/consts"
+	"yxy-go/internal/manager/auth"
+	"yxy-go/internal/manager/device"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/types"
 	"yxy-go/internal/utils/yxyClient"
 	"yxy-go/pkg/xerr"
 
+	"github.com/shopspring/decimal"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/mr"
+)
+
+const (
+	// pastRecordsCacheTTL 已经过去的日期的消费记录缓存时间
+	pastRecordsCacheTTL = 7 * 24 * time.Hour
+	// todayRecordsCacheTTL 当天消费记录缓存时间
+	todayRecordsCacheTTL = 5 * time.Minute
 )
 
 type GetCardConsumptionRecordsLogic struct {
 	logx.Logger
-	ctx    context.Context
-	svcCtx *svc.ServiceContext
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *device.DeviceProfileManager
+	authManager   *auth.CardAuthManager
 }
 
 func NewGetCardConsumptionRecordsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCardConsumptionRecordsLogic {
 	return &GetCardConsumptionRecordsLogic{
-		Logger: logx.WithContext(ctx),
-		ctx:    ctx,
-		svcCtx: svcCtx,
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
+		authManager:   auth.NewCardAuthManager(ctx, svcCtx),
 	}
 }
 
+// CardConsumptionYxyRecord 易校园返回的一条交易记录
+type CardConsumptionYxyRecord struct {
+	Type           string `json:"type"`
+	Time           string `json:"time"`
+	Dealtime       string `json:"dealtime"`
+	Address        string `json:"address"`
+	FeeName        string `json:"feeName"`
+	Serialno       string `json:"serialno"`
+	Money          string `json:"money"`
+	BusinessName   string `json:"businessName"`
+	BusinessNum    string `json:"businessNum"`
+	FeeNum         string `json:"feeNum"`
+	AccName        string `json:"accName"`
+	AccNum         string `json:"accNum"`
+	PerCode        string `json:"perCode"`
+	EWalletId      string `json:"eWalletId"`
+	MonCard        string `json:"monCard"`
+	AfterMon       string `json:"afterMon"`
+	ConcessionsMon string `json:"concessionsMon"`
+}
+
 type GetCardConsumptionRecordsYxyResp struct {
 	// StatusCode int    `json:"statusCode"` // 由于响应中该字段类型不统一(int/string), 因此不解析, 改由Success判断
-	Message string `json:"message"`
-	Rows    []struct {
-		// Type           string `json:"type"`
-		Time string `json:"time"`
-		// Dealtime       string `json:"dealtime"`
-		Address string `json:"address"`
-		// FeeName        string `json:"feeName"`
-		// Serialno       string `json:"serialno"`
-		Money string `json:"money"`
-		// BusinessName   string `json:"businessName"`
-		// BusinessNum    string `json:"businessNum"`
-		// FeeNum         string `json:"feeNum"`
-		// AccName        string `json:"accName"`
-		// AccNum         string `json:"accNum"`
-		// PerCode        string `json:"perCode"`
-		// EWalletId      string `json:"eWalletId"`
-		// MonCard        string `json:"monCard"`
-		// AfterMon       string `json:"afterMon"`
-		// ConcessionsMon string `json:"concessionsMon"`
-	} `json:"rows"`
+	Message string                     `json:"message"`
+	Rows    []CardConsumptionYxyRecord `json:"rows"`
 	// Total   int  `json:"total"`
 	Success bool `json:"success"`
 }
 
-func (l *GetCardConsumptionRecordsLogic) GetCardConsumptionRecords(req *types.GetCardConsumptionRecordsReq) (resp *types.GetCardConsumptionRecordsResp, err error) {
-	if _, err := time.Parse("20060102", req.QueryTime); err != nil {
-		return nil, xerr.WithCode(xerr.ErrParam, err.Error())
-	}
-
-	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParam(req.DeviceID)
-	yxyReq["ymId"] = req.UID
+// fetchCardConsumptionRecords 获取某一天的消费记录, queryTime 格式为 20060102
+func (l *GetCardConsumptionRecordsLogic) fetchCardConsumptionRecords(uid, queryTime string, profile *yxyClient.DeviceProfile) ([]CardConsumptionYxyRecord, error) {
+	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(profile)
+	yxyReq["ymId"] = uid
 	yxyReq["schoolCode"] = consts.SCHOOL_CODE
-	yxyReq["queryTime"] = req.QueryTime
+	yxyReq["queryTime"] = queryTime
 
 	var yxyResp GetCardConsumptionRecordsYxyResp
-	r, err := yxyClient.HttpSendPost(consts.GET_CARD_CONSUMPTION_RECORDS_URL, yxyReq, yxyHeaders, &yxyResp)
+	r, err := yxyClient.HttpSendPostWithContext(l.ctx, consts.GET_CARD_CONSUMPTION_RECORDS_URL, yxyReq, yxyHeaders, &yxyResp)
 	if err != nil {
 		return nil, err
 	}
@@ -82,17 +100,206 @@ func (l *GetCardConsumptionRecordsLogic) GetCardConsumptionRecords(req *types.Ge
 		return nil, xerr.WithCode(errCode, fmt.Sprintf("yxy response: %v", r))
 	}
 
-	var records []types.CardConsumptionRecord
-	for _, row := range yxyResp.Rows {
-		record := types.CardConsumptionRecord{
-			Address: row.Address,
-			Money:   row.Money,
-			Time:    row.Time,
+	if yxyResp.Rows == nil {
+		return make([]CardConsumptionYxyRecord, 0), nil
+	}
+	return yxyResp.Rows, nil
+}
+
+func (l *GetCardConsumptionRecordsLogic) getCacheKey(uid, queryTime string) string {
+	return "card:consumption_rows:" + uid + ":" + queryTime
+}
+
+// getDailyConsumptionRecords 获取某一天的消费记录, 优先从缓存获取;
+// 已经过去的日期记录不会再变化, 缓存较长时间, 当天的记录只做短暂缓存
+func (l *GetCardConsumptionRecordsLogic) getDailyConsumptionRecords(uid, queryTime string, profile *yxyClient.DeviceProfile) ([]CardConsumptionYxyRecord, error) {
+	key := l.getCacheKey(uid, queryTime)
+	raw, err := l.svcCtx.Rdb.Get(l.ctx, key).Result()
+	if err == nil {
+		var records []CardConsumptionYxyRecord
+		if err = json.Unmarshal([]byte(raw), &records); err == nil {
+			return records, nil
 		}
-		records = append(records, record)
 	}
 
+	records, err := l.fetchCardConsumptionRecords(uid, queryTime, profile)
+	if err != nil {
+		return nil, err
+	}
+	l.recordWalletNos(uid, records)
+
+	ttl := todayRecordsCacheTTL
+	if queryTime < time.Now().Format("20060102") {
+		ttl = pastRecordsCacheTTL
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		l.Logger.Errorf("消费记录序列化失败: %v", err)
+		return records, nil
+	}
+	l.svcCtx.Rdb.Set(l.ctx, key, data, ttl)
+	return records, nil
+}
+
+// recordWalletNos 把消费记录中新出现的钱包补充到已缓存的钱包列表, 列表未缓存时由查询余额时重新获取
+func (l *GetCardConsumptionRecordsLogic) recordWalletNos(uid string, records []CardConsumptionYxyRecord) {
+	members := make([]any, 0, len(records))
+	for _, record := range records {
+		if record.EWalletId != "" {
+			members = append(members, record.EWalletId)
+		}
+	}
+	if len(members) == 0 {
+		return
+	}
+
+	key := getWalletsCacheKey(uid)
+	exists, err := l.svcCtx.Rdb.Exists(l.ctx, key).Result()
+	if err != nil || exists == 0 {
+		return
+	}
+	if err = l.svcCtx.Rdb.SAdd(l.ctx, key, members...).Err(); err != nil {
+		l.Logger.Errorf("更新钱包列表失败: %v", err)
+	}
+}
+
+type dailyConsumptionRecords struct {
+	date    string
+	records []CardConsumptionYxyRecord
+}
+
+// queryDailyRecords 按天并发获取消费记录, 结果按日期倒序排列
+func (l *GetCardConsumptionRecordsLogic) queryDailyRecords(uid string, dates []string, profile *yxyClient.DeviceProfile) ([]dailyConsumptionRecords, error) {
+	days, err := mr.MapReduce(func(source chan<- string) {
+		for _, date := range dates {
+			source <- date
+		}
+	}, func(date string, writer mr.Writer[dailyConsumptionRecords], cancel func(error)) {
+		records, err := l.getDailyConsumptionRecords(uid, date, profile)
+		if err != nil {
+			cancel(err)
+			return
+		}
+		writer.Write(dailyConsumptionRecords{date: date, records: records})
+	}, func(pipe <-chan dailyConsumptionRecords, writer mr.Writer[[]dailyConsumptionRecords], cancel func(error)) {
+		var days []dailyConsumptionRecords
+		for day := range pipe {
+			days = append(days, day)
+		}
+		writer.Write(days)
+	}, mr.WithContext(l.ctx), mr.WithWorkers(l.svcCtx.Config.Card.QueryConcurrency))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].date > days[j].date
+	})
+	return days, nil
+}
+
+// queryRange 解析查询范围并获取范围内每一天的原始记录, 整个范围的查询作为一次调用, 避免登录失效时每一天都触发静默登录
+func (l *GetCardConsumptionRecordsLogic) queryRange(req *types.GetCardConsumptionRecordsReq) ([]dailyConsumptionRecords, error) {
+	dates, err := parseQueryDates(req, l.svcCtx.Config.Card.MaxQueryDays)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := l.deviceManager.GetOrCreateProfile(req.UID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Token != "" {
+		if err = l.authManager.SaveAuthToken(req.UID, req.Token); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := l.authManager.WithAuthToken(req.UID, func(_ string) (any, error) {
+		return l.queryDailyRecords(req.UID, dates, profile)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]dailyConsumptionRecords), nil
+}
+
+// GetCardConsumptionRecords 合并范围内的消费记录, 按时间倒序排列并计算每日小计
+func (l *GetCardConsumptionRecordsLogic) GetCardConsumptionRecords(req *types.GetCardConsumptionRecordsReq) (resp *types.GetCardConsumptionRecordsResp, err error) {
+	days, err := l.queryRange(req)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]types.CardConsumptionRecord, 0)
+	subtotals := make([]types.CardConsumptionSubtotal, 0, len(days))
+	for _, day := range days {
+		total := decimal.Zero
+		for _, row := range day.records {
+			records = append(records, types.CardConsumptionRecord{
+				Address: row.Address,
+				Money:   row.Money,
+				Time:    row.Time,
+			})
+			money, err := parseMoney(row.Money)
+			if err != nil {
+				l.Logger.Errorf("解析消费金额失败: %v", err)
+				continue
+			}
+			total = total.Add(money)
+		}
+		subtotals = append(subtotals, types.CardConsumptionSubtotal{
+			Date:  day.date,
+			Count: len(day.records),
+			Total: total.StringFixed(2),
+		})
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time > records[j].Time
+	})
+
 	return &types.GetCardConsumptionRecordsResp{
-		List: records,
+		List:      records,
+		Subtotals: subtotals,
 	}, nil
 }
+
+// parseQueryDates 解析查询的日期范围, query_time 优先, 兼容单日查询
+func parseQueryDates(req *types.GetCardConsumptionRecordsReq, maxDays int) ([]string, error) {
+	from, to := req.From, req.To
+	if req.QueryTime != "" {
+		from, to = req.QueryTime, req.QueryTime
+	}
+	if to == "" {
+		to = from
+	}
+
+	fromDate, err := time.Parse("20060102", from)
+	if err != nil {
+		return nil, xerr.WithCode(xerr.ErrParam, err.Error())
+	}
+	toDate, err := time.Parse("20060102", to)
+	if err != nil {
+		return nil, xerr.WithCode(xerr.ErrParam, err.Error())
+	}
+	if toDate.Before(fromDate) {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("from %v is after to %v", from, to))
+	}
+	if days := int(toDate.Sub(fromDate).Hours()/24) + 1; days > maxDays {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("query range %d days exceeds limit %d", days, maxDays))
+	}
+
+	var dates []string
+	for date := fromDate; !date.After(toDate); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date.Format("20060102"))
+	}
+	return dates, nil
+}
+
+// parseMoney 解析易校园返回的金额字符串
+func parseMoney(money string) (decimal.Decimal, error) {
+	money = strings.TrimSpace(money)
+	money = strings.TrimSuffix(money, "元")
+	money = strings.TrimPrefix(money, "+")
+	return decimal.NewFromString(money)
+}
@@ -0,0 +1,158 @@
+package card
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/shopspring/decimal"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	TransactionConsume  = "consume"
+	TransactionRecharge = "recharge"
+	TransactionSubsidy  = "subsidy"
+	TransactionOther    = "other"
+)
+
+// transactionTypeKeywords 根据交易类型、费用名称中的关键字划分交易类别
+var transactionTypeKeywords = []struct {
+	Type     string
+	Keywords []string
+}{
+	{TransactionRecharge, []string{"充值", "圈存", "转入", "存款"}},
+	{TransactionSubsidy, []string{"补助", "补贴"}},
+	{TransactionConsume, []string{"消费", "扣款", "支付"}},
+}
+
+type GetCardTransactionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCardTransactionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCardTransactionsLogic {
+	return &GetCardTransactionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCardTransactionsLogic) GetCardTransactions(req *types.GetCardTransactionsReq) (resp *types.GetCardTransactionsResp, err error) {
+	days, err := NewGetCardConsumptionRecordsLogic(l.ctx, l.svcCtx).queryRange(&types.GetCardConsumptionRecordsReq{
+		UID:       req.UID,
+		DeviceID:  req.DeviceID,
+		Token:     req.Token,
+		QueryTime: req.QueryTime,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.CardTransaction, 0)
+	for _, day := range days {
+		for _, row := range day.records {
+			list = append(list, l.toTransaction(row))
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Timestamp > list[j].Timestamp
+	})
+
+	return &types.GetCardTransactionsResp{
+		List:    list,
+		Summary: summarizeTransactions(list),
+	}, nil
+}
+
+// toTransaction 将易校园的交易记录转换为带类型的字段, 无法解析的金额和时间记录日志后置零
+func (l *GetCardTransactionsLogic) toTransaction(row CardConsumptionYxyRecord) types.CardTransaction {
+	amount := l.parseAmount(row.Money)
+	transaction := types.CardTransaction{
+		SerialNo:     row.Serialno,
+		RawType:      row.Type,
+		FeeName:      row.FeeName,
+		Address:      row.Address,
+		BusinessName: row.BusinessName,
+		BusinessNum:  row.BusinessNum,
+		WalletID:     row.EWalletId,
+		Amount:       amount.StringFixed(2),
+		Discount:     l.parseAmount(row.ConcessionsMon).StringFixed(2),
+		BalanceAfter: l.parseAmount(row.AfterMon).StringFixed(2),
+		Time:         row.Time,
+	}
+	transaction.Type = classifyTransaction(row.Type, row.FeeName, amount)
+
+	if t, err := parseRecordTime(row.Time); err == nil {
+		transaction.Time = t.Format("2006-01-02 15:04:05")
+		transaction.Timestamp = t.Unix()
+	} else {
+		l.Logger.Errorf("解析交易时间失败: %v", err)
+	}
+	if t, err := parseRecordTime(row.Dealtime); err == nil {
+		transaction.DealTime = t.Format("2006-01-02 15:04:05")
+	}
+	return transaction
+}
+
+func (l *GetCardTransactionsLogic) parseAmount(money string) decimal.Decimal {
+	if strings.TrimSpace(money) == "" {
+		return decimal.Zero
+	}
+	amount, err := parseMoney(money)
+	if err != nil {
+		l.Logger.Errorf("解析交易金额失败: %v", err)
+		return decimal.Zero
+	}
+	return amount
+}
+
+// classifyTransaction 判断交易类别, 无法通过关键字判断时, 支出视为消费
+func classifyTransaction(rawType, feeName string, amount decimal.Decimal) string {
+	text := rawType + feeName
+	for _, t := range transactionTypeKeywords {
+		for _, keyword := range t.Keywords {
+			if strings.Contains(text, keyword) {
+				return t.Type
+			}
+		}
+	}
+	if amount.IsNegative() {
+		return TransactionConsume
+	}
+	return TransactionOther
+}
+
+// summarizeTransactions 按交易类别汇总金额(绝对值)和笔数
+func summarizeTransactions(list []types.CardTransaction) []types.CardTransactionSummary {
+	totals := make(map[string]*spendingAgg)
+	for _, t := range list {
+		if _, ok := totals[t.Type]; !ok {
+			totals[t.Type] = &spendingAgg{}
+		}
+		// Amount 由 toTransaction 格式化, 一定可以解析
+		amount, _ := decimal.NewFromString(t.Amount)
+		totals[t.Type].add(amount.Abs())
+	}
+
+	summary := make([]types.CardTransactionSummary, 0, 4)
+	for _, typ := range []string{TransactionConsume, TransactionRecharge, TransactionSubsidy, TransactionOther} {
+		agg, ok := totals[typ]
+		if !ok {
+			agg = &spendingAgg{}
+		}
+		summary = append(summary, types.CardTransactionSummary{
+			Type:  typ,
+			Total: agg.total.StringFixed(2),
+			Count: agg.count,
+		})
+	}
+	return summary
+}
@@ -0,0 +1,51 @@
+package device
+
+import (
+	"context"
+	"time"
+
+	deviceManager "yxy-go/internal/manager/device"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/internal/utils/yxyClient"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetDeviceProfileLogic struct {
+	logx.Logger
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *deviceManager.DeviceProfileManager
+}
+
+func NewGetDeviceProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetDeviceProfileLogic {
+	return &GetDeviceProfileLogic{
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		deviceManager: deviceManager.NewDeviceProfileManager(ctx, svcCtx),
+	}
+}
+
+func (l *GetDeviceProfileLogic) GetDeviceProfile(req *types.GetDeviceProfileReq) (resp *types.GetDeviceProfileResp, err error) {
+	profile, err := l.deviceManager.GetOwnProfile(req.UID, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.GetDeviceProfileResp{
+		Profile: toDeviceProfile(profile),
+	}, nil
+}
+
+func toDeviceProfile(profile *yxyClient.DeviceProfile) types.DeviceProfile {
+	return types.DeviceProfile{
+		DeviceID:   profile.DeviceID,
+		Brand:      profile.Brand,
+		MobileType: profile.MobileType,
+		OSVersion:  profile.OSVersion,
+		UserAgent:  profile.UserAgent,
+		CreatedAt:  time.UnixMilli(profile.CreatedAt).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,42 @@
+package device
+
+import (
+	"context"
+
+	deviceManager "yxy-go/internal/manager/device"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ResetDeviceProfileLogic struct {
+	logx.Logger
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *deviceManager.DeviceProfileManager
+}
+
+func NewResetDeviceProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResetDeviceProfileLogic {
+	return &ResetDeviceProfileLogic{
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		deviceManager: deviceManager.NewDeviceProfileManager(ctx, svcCtx),
+	}
+}
+
+// ResetDeviceProfile 重置设备信息, 重置后旧的登录状态将失效, 需要使用新的 device_id 重新登录
+func (l *ResetDeviceProfileLogic) ResetDeviceProfile(req *types.ResetDeviceProfileReq) (resp *types.ResetDeviceProfileResp, err error) {
+	if _, err = l.deviceManager.GetOwnProfile(req.UID, req.DeviceID); err != nil {
+		return nil, err
+	}
+	profile, err := l.deviceManager.ResetProfile(req.UID, req.NewDeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ResetDeviceProfileResp{
+		Profile: toDeviceProfile(profile),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package electricity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/internal/utils/exporter"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ExportElectricityRechargeRecordsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportElectricityRechargeRecordsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportElectricityRechargeRecordsLogic {
+	return &ExportElectricityRechargeRecordsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportElectricityRechargeRecordsLogic) ExportElectricityRechargeRecords(req *types.ExportElectricityRechargeRecordsReq) (*exporter.File, error) {
+	resp, err := NewGetElectricityRechargeRecordsLogic(l.ctx, l.svcCtx).GetElectricityRechargeRecords(&types.GetElectricityRechargeRecordsReq{
+		Uid:           req.Uid,
+		Campus:        req.Campus,
+		Page:          req.Page,
+		RoomStrConcat: req.RoomStrConcat,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	table := exporter.Table{Headers: []string{"充值时间", "金额"}}
+	for _, record := range resp.List {
+		table.Rows = append(table.Rows, []string{record.Datetime, record.Money})
+	}
+
+	name := fmt.Sprintf("electricity_recharge_records_%v_%v", strings.ReplaceAll(req.RoomStrConcat, "#", "-"), req.Page)
+	return exporter.ExportTable(req.Format, name, table, resp)
+}
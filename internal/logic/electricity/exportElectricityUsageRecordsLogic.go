@@ -0,0 +1,46 @@
+package electricity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"yxy-go/internal/svc"
+	"yxy-go/internal/types"
+	"yxy-go/internal/utils/exporter"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ExportElectricityUsageRecordsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportElectricityUsageRecordsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportElectricityUsageRecordsLogic {
+	return &ExportElectricityUsageRecordsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ExportElectricityUsageRecordsLogic) ExportElectricityUsageRecords(req *types.ExportElectricityUsageRecordsReq) (*exporter.File, error) {
+	resp, err := NewGetElectricityUsageRecordsLogic(l.ctx, l.svcCtx).GetElectricityUsageRecords(&types.GetElectricityUsageRecordsReq{
+		Uid:           req.Uid,
+		Campus:        req.Campus,
+		RoomStrConcat: req.RoomStrConcat,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	table := exporter.Table{Headers: []string{"日期", "用电量"}}
+	for _, record := range resp.List {
+		table.Rows = append(table.Rows, []string{record.Datetime, record.Usage})
+	}
+
+	name := fmt.Sprintf("electricity_usage_records_%v", strings.ReplaceAll(req.RoomStrConcat, "#", "-"))
+	return exporter.ExportTable(req.Format, name, table, resp)
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"yxy-go/internal/manager/auth"
+	"yxy-go/internal/manager/device"
 
 	"yxy-go/internal/consts"
 	"yxy-go/internal/svc"
@@ -18,17 +19,19 @@ import (
 
 type GetElectricityRechargeRecordsLogic struct {
 	logx.Logger
-	ctx        context.Context
-	svcCtx     *svc.ServiceContext
-	authManger *auth.ElectricityAuthManager
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	authManger    *auth.ElectricityAuthManager
+	deviceManager *device.DeviceProfileManager
 }
 
 func NewGetElectricityRechargeRecordsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetElectricityRechargeRecordsLogic {
 	return &GetElectricityRechargeRecordsLogic{
-		Logger:     logx.WithContext(ctx),
-		ctx:        ctx,
-		svcCtx:     svcCtx,
-		authManger: auth.NewElectricityAuthManager(ctx, svcCtx),
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		authManger:    auth.NewElectricityAuthManager(ctx, svcCtx),
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
 	}
 }
 
@@ -78,7 +81,11 @@ func (l *GetElectricityRechargeRecordsLogic) fetchElectricityRechargeRecords(req
 		"platform":     "YUNMA_APP",
 	}
 
-	_, yxyHeaders := yxyClient.GetYxyBaseReqParam("")
+	profile, err := l.deviceManager.ProfileOf(req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	_, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(profile)
 	yxyHeaders["Cookie"] = "shiroJID=" + token
 
 	var records []types.ElectricityRechargeRecord
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"yxy-go/internal/consts"
 	"yxy-go/internal/manager/auth"
+	"yxy-go/internal/manager/device"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/types"
 	"yxy-go/internal/utils/yxyClient"
@@ -16,17 +17,19 @@ import (
 
 type GetElectricitySurplusLogic struct {
 	logx.Logger
-	ctx        context.Context
-	svcCtx     *svc.ServiceContext
-	authManger *auth.ElectricityAuthManager
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	authManger    *auth.ElectricityAuthManager
+	deviceManager *device.DeviceProfileManager
 }
 
 func NewGetElectricitySurplusLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetElectricitySurplusLogic {
 	return &GetElectricitySurplusLogic{
-		Logger:     logx.WithContext(ctx),
-		ctx:        ctx,
-		svcCtx:     svcCtx,
-		authManger: auth.NewElectricityAuthManager(ctx, svcCtx),
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		authManger:    auth.NewElectricityAuthManager(ctx, svcCtx),
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
 	}
 }
 
@@ -128,11 +131,15 @@ func (l *GetElectricitySurplusLogic) fetchElectricitySurplus(req *types.GetElect
 		"platform": "YUNMA_APP",
 	}
 
-	_, yxyHeaders := yxyClient.GetYxyBaseReqParam("")
+	profile, err := l.deviceManager.ProfileOf(req.Uid)
+	if err != nil {
+		return nil, err
+	}
+	_, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(profile)
 	yxyHeaders["Cookie"] = "shiroJID=" + token
 
 	var yxyResp QueryElectricityBindYxyResp
-	r, err := yxyClient.HttpSendPost(consts.QUERY_ELECTRICITY_BIND_URL, yxyReq, yxyHeaders, &yxyResp)
+	r, err := yxyClient.HttpSendPostWithContext(l.ctx, consts.QUERY_ELECTRICITY_BIND_URL, yxyReq, yxyHeaders, &yxyResp)
 	if err != nil {
 		return nil, err
 	}
@@ -167,7 +174,7 @@ func (l *GetElectricitySurplusLogic) fetchElectricitySurplus(req *types.GetElect
 	switch req.Campus {
 	case "zhpf":
 		var yxyZhpfResp GetElectricityZhpfSurplusYxyResp
-		r, err := yxyClient.HttpSendPost(consts.GET_ELECTRICITY_ZHPF_SURPLUS_URL, yxyReq, yxyHeaders, &yxyZhpfResp)
+		r, err := yxyClient.HttpSendPostWithContext(l.ctx, consts.GET_ELECTRICITY_ZHPF_SURPLUS_URL, yxyReq, yxyHeaders, &yxyZhpfResp)
 		if err != nil {
 			return nil, err
 		}
@@ -184,7 +191,7 @@ func (l *GetElectricitySurplusLogic) fetchElectricitySurplus(req *types.GetElect
 
 	case "mgs":
 		var yxyMgsResp GetElectricityMgsSurplusYxyResp
-		r, err := yxyClient.HttpSendPost(consts.GET_ELECTRICITY_MGS_SURPLUS_URL, yxyReq, yxyHeaders, &yxyMgsResp)
+		r, err := yxyClient.HttpSendPostWithContext(l.ctx, consts.GET_ELECTRICITY_MGS_SURPLUS_URL, yxyReq, yxyHeaders, &yxyMgsResp)
 		if err != nil {
 			return nil, err
 		}
@@ -35,7 +35,7 @@ func NewGetCaptchaImageLogic(ctx context.Context, svcCtx *svc.ServiceContext) *G
 }
 
 func (l *GetCaptchaImageLogic) GetCaptchaImage(req *types.GetCaptchaImageReq) (resp *types.GetCaptchaImageResp, err error) {
-	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParam(req.DeviceID)
+	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(yxyClient.NewDeviceProfile(req.DeviceID))
 	yxyReq["securityToken"] = req.SecurityToken
 
 	var yxyResp GetCaptchaImageYxyResp
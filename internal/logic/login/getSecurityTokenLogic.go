@@ -38,7 +38,7 @@ func NewGetSecurityTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 }
 
 func (l *GetSecurityTokenLogic) GetSecurityToken(req *types.GetSecurityTokenReq) (resp *types.GetSecurityTokenResp, err error) {
-	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParam(req.DeviceID)
+	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(yxyClient.NewDeviceProfile(req.DeviceID))
 	yxyReq["sceneCode"] = "app_user_login"
 
 	var yxyResp GetSecurityTokenYxyResp
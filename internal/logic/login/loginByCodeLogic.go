@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"yxy-go/internal/consts"
+	"yxy-go/internal/manager/device"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/types"
 	"yxy-go/internal/utils/yxyClient"
@@ -16,15 +17,17 @@ import (
 
 type LoginByCodeLogic struct {
 	logx.Logger
-	ctx    context.Context
-	svcCtx *svc.ServiceContext
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *device.DeviceProfileManager
 }
 
 func NewLoginByCodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginByCodeLogic {
 	return &LoginByCodeLogic{
-		Logger: logx.WithContext(ctx),
-		ctx:    ctx,
-		svcCtx: svcCtx,
+		Logger:        logx.WithContext(ctx),
+		ctx:           ctx,
+		svcCtx:        svcCtx,
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
 	}
 }
 
@@ -75,17 +78,19 @@ type LoginByCodeYxyResp struct {
 }
 
 func (l *LoginByCodeLogic) LoginByCode(req *types.LoginByCodeReq) (resp *types.LoginByCodeResp, err error) {
-	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParam(req.DeviceID)
+	// 登录前还不知道 uid, 设备信息由 deviceID 确定性生成, 与登录前各步骤使用的设备信息一致
+	profile := yxyClient.NewDeviceProfile(req.DeviceID)
+	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(profile)
 	yxyReq["mobilePhone"] = req.PhoneNum
 	yxyReq["verificationCode"] = req.Code
 	yxyReq["appAllVersion"] = consts.APP_ALL_VERSION
 	yxyReq["appPlatform"] = "Android"
-	yxyReq["brand"] = "Android"
+	yxyReq["brand"] = profile.Brand
 	yxyReq["clientId"] = consts.CLIENT_ID
 	yxyReq["invitationCode"] = ""
-	yxyReq["mobileType"] = "Android for arm64"
+	yxyReq["mobileType"] = profile.MobileType
 	yxyReq["osType"] = "Android"
-	yxyReq["osVersion"] = "12"
+	yxyReq["osVersion"] = profile.OSVersion
 
 	var yxyResp LoginByCodeYxyResp
 	r, err := yxyClient.HttpSendPost(consts.LOGIN_BY_CODE_URL, yxyReq, yxyHeaders, &yxyResp)
@@ -105,6 +110,11 @@ func (l *LoginByCodeLogic) LoginByCode(req *types.LoginByCodeReq) (resp *types.L
 		return nil, xerr.WithCode(errCode, fmt.Sprintf("yxy response: %v", r))
 	}
 
+	// 登录后易校园会将账号与该设备绑定, 保存设备信息供后续请求复用
+	if err = l.deviceManager.SaveProfile(yxyResp.Data.Id, profile); err != nil {
+		return nil, err
+	}
+
 	return &types.LoginByCodeResp{
 		UID:            yxyResp.Data.Id,
 		BindCardStatus: yxyResp.Data.BindCardStatus,
@@ -43,7 +43,7 @@ type SendCodeYxyResp struct {
 }
 
 func (l *SendCodeLogic) SendCode(req *types.SendCodeReq) (resp *types.SendCodeResp, err error) {
-	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParam(req.DeviceID)
+	yxyReq, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(yxyClient.NewDeviceProfile(req.DeviceID))
 	yxyReq["mobilePhone"] = req.PhoneNum
 	yxyReq["securityToken"] = req.SecurityToken
 	yxyReq["sendCount"] = 1
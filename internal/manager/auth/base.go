@@ -24,3 +24,4 @@ const cacheTTL = 24 * time.Hour
 // 编译期断言, 检查接口是否全部实现
 var _ AuthManager = (*BusAuthManager)(nil)
 var _ AuthManager = (*ElectricityAuthManager)(nil)
+var _ AuthManager = (*CardAuthManager)(nil)
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"time"
 	"yxy-go/internal/consts"
+	"yxy-go/internal/manager/device"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/utils/yxyClient"
 	"yxy-go/pkg/xerr"
@@ -17,17 +18,19 @@ import (
 
 type BusAuthManager struct {
 	logx.Logger
-	ctx      context.Context
-	svcCtx   *svc.ServiceContext
-	cacheTTL time.Duration
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	cacheTTL      time.Duration
+	deviceManager *device.DeviceProfileManager
 }
 
 func NewBusAuthManager(ctx context.Context, svcCtx *svc.ServiceContext) *BusAuthManager {
 	return &BusAuthManager{
-		ctx:      ctx,
-		Logger:   logx.WithContext(ctx),
-		svcCtx:   svcCtx,
-		cacheTTL: 24 * time.Hour,
+		ctx:           ctx,
+		Logger:        logx.WithContext(ctx),
+		svcCtx:        svcCtx,
+		cacheTTL:      24 * time.Hour,
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
 	}
 }
 
@@ -72,7 +75,11 @@ func (l *BusAuthManager) FetchAuthToken(uid string) (string, error) {
 
 	// 4. WX_Auth
 	var fetchResp wxAuthResp
-	_, headers := yxyClient.GetYxyBaseReqParam("")
+	profile, err := l.deviceManager.ProfileOf(uid)
+	if err != nil {
+		return "", err
+	}
+	_, headers := yxyClient.GetYxyBaseReqParamByProfile(profile)
 	_, err = yxyClient.HttpSendPost(consts.GET_BUS_AUTH_TOKEN_URL, map[string]interface{}{
 		"corpcode": corpcode,
 		"openid":   2014120230,
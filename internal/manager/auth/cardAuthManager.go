@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"yxy-go/internal/manager/device"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/utils/yxyClient"
+	"yxy-go/pkg/xerr"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// sessionTTL 易校园登录token的保存时间, token 只在静默登录时使用, 因此比其他token保存更久
+const sessionTTL = 30 * 24 * time.Hour
+
+// silentLoginBackoff 静默登录失败后, 在该时长内不再重试, 避免每个请求都重复静默登录
+const silentLoginBackoff = 10 * time.Minute
+
+// CardAuthManager 维护服务端保存的易校园登录状态, 账号被登出时自动静默登录
+type CardAuthManager struct {
+	logx.Logger
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *device.DeviceProfileManager
+}
+
+func NewCardAuthManager(ctx context.Context, svcCtx *svc.ServiceContext) *CardAuthManager {
+	return &CardAuthManager{
+		ctx:           ctx,
+		Logger:        logx.WithContext(ctx),
+		svcCtx:        svcCtx,
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
+	}
+}
+
+// FetchAuthToken 使用已保存的设备信息和token静默登录, 获取新的token; 未保存token时无法静默登录
+func (l *CardAuthManager) FetchAuthToken(uid string) (string, error) {
+	token, err := l.getCachedAuthToken(uid)
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", xerr.WithCode(xerr.ErrAccountLoggedOut, fmt.Sprintf("No saved token, UID: %v", uid))
+	}
+
+	profile, err := l.deviceManager.GetProfile(uid)
+	if err != nil {
+		return "", err
+	}
+	token, err = yxyClient.LoginBySilent(l.ctx, profile, uid, "", token)
+	if err != nil {
+		return "", err
+	}
+	l.Logger.Infof("%s静默登录成功", uid)
+	return token, nil
+}
+
+// getCacheKey 获取缓存token的key
+func (l *CardAuthManager) getCacheKey(uid string) string {
+	return "card:auth_token:" + uid
+}
+
+func (l *CardAuthManager) getFailedCacheKey(uid string) string {
+	return "card:silent_login_failed:" + uid
+}
+
+// refreshCachedAuthToken 刷新缓存中的AuthToken; 静默登录失败时暂停重试,
+// 账号已登出或不存在时删除保存的token, 需要客户端重新传入
+func (l *CardAuthManager) refreshCachedAuthToken(uid string) (string, error) {
+	failedKey := l.getFailedCacheKey(uid)
+	if n, err := l.svcCtx.Rdb.Exists(l.ctx, failedKey).Result(); err == nil && n > 0 {
+		return "", xerr.WithCode(xerr.ErrAccountLoggedOut, fmt.Sprintf("Silent login failed recently, UID: %v", uid))
+	}
+	token, err := l.FetchAuthToken(uid)
+	if err != nil {
+		l.svcCtx.Rdb.Set(l.ctx, failedKey, 1, silentLoginBackoff)
+		if isLoggedOut(err) {
+			l.svcCtx.Rdb.Del(l.ctx, l.getCacheKey(uid))
+		}
+		return "", err
+	}
+	if err = l.SaveAuthToken(uid, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// getCachedAuthToken 获取authToken, 一卡通接口本身不依赖token, 因此缓存中不存在时不主动登录
+func (l *CardAuthManager) getCachedAuthToken(uid string) (string, error) {
+	key := l.getCacheKey(uid)
+	token, err := l.svcCtx.Rdb.Get(l.ctx, key).Result()
+	if err == nil {
+		return token, nil
+	}
+
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	} else {
+		return "", errors.New("获取缓存Token失败, redis异常")
+	}
+}
+
+// SaveAuthToken 保存客户端传入或静默登录得到的token; token变化时可以立即重新静默登录
+func (l *CardAuthManager) SaveAuthToken(uid, token string) error {
+	prev, err := l.getCachedAuthToken(uid)
+	if err != nil {
+		return err
+	}
+	if err = l.svcCtx.Rdb.Set(l.ctx, l.getCacheKey(uid), token, sessionTTL).Err(); err != nil {
+		return errors.New("保存Token失败, redis异常")
+	}
+	if prev != token {
+		l.svcCtx.Rdb.Del(l.ctx, l.getFailedCacheKey(uid))
+	}
+	return nil
+}
+
+// WithAuthToken 包装需要登录状态的业务函数, 账号被登出时静默登录并重试一次
+func (l *CardAuthManager) WithAuthToken(uid string, fn func(token string) (any, error)) (any, error) {
+	// 1. 从缓存获取 token
+	token, err := l.getCachedAuthToken(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 调用回调函数
+	result, err := fn(token)
+	if err == nil || !isLoggedOut(err) {
+		return result, err
+	}
+
+	// 3. 登录状态失效, 未保存token时无法静默登录, 直接返回原错误
+	if token == "" {
+		return nil, err
+	}
+	l.Logger.Infof("%s登录状态失效, 尝试静默登录: %v", uid, err)
+	if token, err = l.refreshCachedAuthToken(uid); err != nil {
+		return nil, err
+	}
+	return fn(token)
+}
+
+// isLoggedOut 判断错误是否由登录状态失效导致
+func isLoggedOut(err error) bool {
+	var e *xerr.ErrCode
+	if !errors.As(err, &e) {
+		return false
+	}
+	return e.Code() == xerr.ErrAccountLoggedOut || e.Code() == xerr.ErrUserNotFound
+}
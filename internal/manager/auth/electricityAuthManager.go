@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 	"yxy-go/internal/consts"
+	"yxy-go/internal/manager/device"
 	"yxy-go/internal/svc"
 	"yxy-go/internal/utils/yxyClient"
 	"yxy-go/pkg/xerr"
@@ -24,21 +25,27 @@ type getAuthTokenResp struct {
 
 type ElectricityAuthManager struct {
 	logx.Logger
-	ctx    context.Context
-	svcCtx *svc.ServiceContext
+	ctx           context.Context
+	svcCtx        *svc.ServiceContext
+	deviceManager *device.DeviceProfileManager
 }
 
 func NewElectricityAuthManager(ctx context.Context, svcCtx *svc.ServiceContext) *ElectricityAuthManager {
 	return &ElectricityAuthManager{
-		ctx:    ctx,
-		Logger: logx.WithContext(ctx),
-		svcCtx: svcCtx,
+		ctx:           ctx,
+		Logger:        logx.WithContext(ctx),
+		svcCtx:        svcCtx,
+		deviceManager: device.NewDeviceProfileManager(ctx, svcCtx),
 	}
 }
 
 // FetchAuthToken 发送请求获取AuthToken
 func (l *ElectricityAuthManager) FetchAuthToken(uid string) (string, error) {
-	_, yxyHeaders := yxyClient.GetYxyBaseReqParam("")
+	profile, err := l.deviceManager.ProfileOf(uid)
+	if err != nil {
+		return "", err
+	}
+	_, yxyHeaders := yxyClient.GetYxyBaseReqParamByProfile(profile)
 	yxyReq := map[string]string{
 		"bindSkip":    "1",
 		"authType":    "2",
@@ -51,6 +58,7 @@ func (l *ElectricityAuthManager) FetchAuthToken(uid string) (string, error) {
 
 	client := yxyClient.GetClient()
 	r, err := client.R().
+		SetContext(l.ctx).
 		SetHeaders(yxyHeaders).
 		SetQueryParams(yxyReq).
 		Get(consts.GET_AUTH_CODE_URL)
@@ -73,7 +81,7 @@ func (l *ElectricityAuthManager) FetchAuthToken(uid string) (string, error) {
 
 	var authResp getAuthTokenResp
 
-	r, err = yxyClient.HttpSendPost(consts.GET_AUTH_TOKEN_URL,
+	r, err = yxyClient.HttpSendPostWithContext(l.ctx, consts.GET_AUTH_TOKEN_URL,
 		map[string]interface{}{
 			"authType": "2",
 			"code":     ymCode,
@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"yxy-go/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AnnouncementSubscriptionManager 管理校车公告订阅, 订阅用户的 UID 保存在同一个 redis set 中
+type AnnouncementSubscriptionManager struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAnnouncementSubscriptionManager(ctx context.Context, svcCtx *svc.ServiceContext) *AnnouncementSubscriptionManager {
+	return &AnnouncementSubscriptionManager{
+		ctx:    ctx,
+		Logger: logx.WithContext(ctx),
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *AnnouncementSubscriptionManager) getCacheKey() string {
+	return "bus:announcement:subscribers"
+}
+
+// ListAll 获取全部订阅用户的 UID
+func (l *AnnouncementSubscriptionManager) ListAll() ([]string, error) {
+	uids, err := l.svcCtx.Rdb.SMembers(l.ctx, l.getCacheKey()).Result()
+	if err != nil {
+		return nil, errors.New("获取校车公告订阅失败, redis异常")
+	}
+	return uids, nil
+}
+
+// Subscribe 订阅校车公告, 重复订阅不报错
+func (l *AnnouncementSubscriptionManager) Subscribe(uid string) error {
+	if err := l.svcCtx.Rdb.SAdd(l.ctx, l.getCacheKey(), uid).Err(); err != nil {
+		return errors.New("保存校车公告订阅失败, redis异常")
+	}
+	return nil
+}
+
+// Unsubscribe 取消订阅校车公告
+func (l *AnnouncementSubscriptionManager) Unsubscribe(uid string) error {
+	if err := l.svcCtx.Rdb.SRem(l.ctx, l.getCacheKey(), uid).Err(); err != nil {
+		return errors.New("删除校车公告订阅失败, redis异常")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package bus
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"yxy-go/internal/svc"
+	"yxy-go/pkg/xerr"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SeatWatch 用户对某一线路某一发车时间的余票提醒
+type SeatWatch struct {
+	ID            string `json:"id"`
+	UID           string `json:"uid"`
+	BusID         string `json:"bus_id"`
+	DepartureTime string `json:"departure_time"` // 发车时间, 格式为 15:04, 匹配每天的该班次
+	CreatedAt     int64  `json:"created_at"`
+}
+
+// SeatWatchManager 管理余票提醒, 全部提醒保存在同一个 redis hash 中, 便于定时任务遍历
+type SeatWatchManager struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSeatWatchManager(ctx context.Context, svcCtx *svc.ServiceContext) *SeatWatchManager {
+	return &SeatWatchManager{
+		ctx:    ctx,
+		Logger: logx.WithContext(ctx),
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SeatWatchManager) getCacheKey() string {
+	return "bus:seat_watches"
+}
+
+func (l *SeatWatchManager) genID(uid, busID, departureTime string) string {
+	sum := md5.Sum([]byte(uid + "|" + busID + "|" + departureTime))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// ListAll 获取全部余票提醒
+func (l *SeatWatchManager) ListAll() ([]SeatWatch, error) {
+	raws, err := l.svcCtx.Rdb.HGetAll(l.ctx, l.getCacheKey()).Result()
+	if err != nil {
+		return nil, errors.New("获取余票提醒失败, redis异常")
+	}
+	watches := make([]SeatWatch, 0, len(raws))
+	for _, raw := range raws {
+		var watch SeatWatch
+		if err = json.Unmarshal([]byte(raw), &watch); err != nil {
+			l.Logger.Errorf("余票提醒反序列化失败: %v", err)
+			continue
+		}
+		watches = append(watches, watch)
+	}
+	sort.Slice(watches, func(i, j int) bool {
+		return watches[i].CreatedAt < watches[j].CreatedAt
+	})
+	return watches, nil
+}
+
+// List 获取用户的余票提醒
+func (l *SeatWatchManager) List(uid string) ([]SeatWatch, error) {
+	all, err := l.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	watches := make([]SeatWatch, 0)
+	for _, watch := range all {
+		if watch.UID == uid {
+			watches = append(watches, watch)
+		}
+	}
+	return watches, nil
+}
+
+// Create 创建余票提醒, 相同线路和发车时间的提醒已存在时直接返回
+func (l *SeatWatchManager) Create(uid, busID, departureTime string) (*SeatWatch, error) {
+	if _, err := time.Parse("15:04", departureTime); err != nil {
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("invalid departure_time: %v", departureTime))
+	}
+
+	watches, err := l.List(uid)
+	if err != nil {
+		return nil, err
+	}
+	id := l.genID(uid, busID, departureTime)
+	for _, watch := range watches {
+		if watch.ID == id {
+			return &watch, nil
+		}
+	}
+	if len(watches) >= l.svcCtx.Config.BusService.MaxSeatWatches {
+		return nil, xerr.WithCode(xerr.ErrBusSeatWatchLimit, fmt.Sprintf("UID: %v, limit: %d", uid, l.svcCtx.Config.BusService.MaxSeatWatches))
+	}
+
+	watch := &SeatWatch{
+		ID:            id,
+		UID:           uid,
+		BusID:         busID,
+		DepartureTime: departureTime,
+		CreatedAt:     time.Now().Unix(),
+	}
+	data, err := json.Marshal(watch)
+	if err != nil {
+		return nil, err
+	}
+	if err = l.svcCtx.Rdb.HSet(l.ctx, l.getCacheKey(), id, data).Err(); err != nil {
+		return nil, errors.New("保存余票提醒失败, redis异常")
+	}
+	return watch, nil
+}
+
+// Delete 删除用户的余票提醒
+func (l *SeatWatchManager) Delete(uid, id string) error {
+	watches, err := l.List(uid)
+	if err != nil {
+		return err
+	}
+	for _, watch := range watches {
+		if watch.ID == id {
+			if err = l.svcCtx.Rdb.HDel(l.ctx, l.getCacheKey(), id).Err(); err != nil {
+				return errors.New("删除余票提醒失败, redis异常")
+			}
+			return nil
+		}
+	}
+	return xerr.WithCode(xerr.ErrBusSeatWatchNotFound, fmt.Sprintf("UID: %v, ID: %v", uid, id))
+}
@@ -0,0 +1,192 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"yxy-go/internal/svc"
+	"yxy-go/pkg/xerr"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 多个实例同时使用账号池, 账号的选取和结果记录在 redis 中原子地读改写
+var (
+	// acquireUIDScript KEYS[1] 账号池, ARGV[1] 当前毫秒时间戳, ARGV[2:] 配置中的账号
+	acquireUIDScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local selected, selectedAccount
+for i = 2, #ARGV do
+	local raw = redis.call("HGET", KEYS[1], ARGV[i])
+	local account = {uid = ARGV[i]}
+	if raw then
+		local ok, decoded = pcall(cjson.decode, raw)
+		if ok then
+			account = decoded
+			account.uid = ARGV[i]
+		end
+	end
+	if (tonumber(account.quarantined_until) or 0) <= now then
+		if selected == nil or (tonumber(account.last_used_at) or 0) < (tonumber(selectedAccount.last_used_at) or 0) then
+			selected, selectedAccount = ARGV[i], account
+		end
+	end
+end
+if selected == nil then
+	return false
+end
+selectedAccount.last_used_at = now
+redis.call("HSET", KEYS[1], selected, cjson.encode(selectedAccount))
+return selected`)
+	// reportUIDScript KEYS[1] 账号池, ARGV[1] 账号, ARGV[2] 失败原因, 成功时为空,
+	// ARGV[3] 隔离截止的毫秒时间戳, 不隔离时为 0
+	reportUIDScript = redis.NewScript(`
+local raw = redis.call("HGET", KEYS[1], ARGV[1])
+local account = {uid = ARGV[1]}
+if raw then
+	local ok, decoded = pcall(cjson.decode, raw)
+	if ok then
+		account = decoded
+	end
+end
+if ARGV[2] == "" then
+	account.successes = (tonumber(account.successes) or 0) + 1
+	account.consecutive_failures = 0
+else
+	account.failures = (tonumber(account.failures) or 0) + 1
+	account.consecutive_failures = (tonumber(account.consecutive_failures) or 0) + 1
+	account.last_error = ARGV[2]
+	if tonumber(ARGV[3]) > 0 then
+		account.quarantined_until = tonumber(ARGV[3])
+	end
+end
+redis.call("HSET", KEYS[1], ARGV[1], cjson.encode(account))
+return 1`)
+)
+
+// ServiceAccount 校车服务账号的使用情况
+type ServiceAccount struct {
+	UID                 string `json:"uid"`
+	LastUsedAt          int64  `json:"last_used_at"`
+	Successes           int64  `json:"successes"`
+	Failures            int64  `json:"failures"`
+	ConsecutiveFailures int64  `json:"consecutive_failures"`
+	QuarantinedUntil    int64  `json:"quarantined_until"`
+	LastError           string `json:"last_error"`
+}
+
+// Quarantined 账号是否处于隔离期
+func (a *ServiceAccount) Quarantined(now time.Time) bool {
+	return a.QuarantinedUntil > now.UnixMilli()
+}
+
+// UIDPoolManager 管理用于爬取校车信息的服务账号池, 按最久未使用的顺序轮换账号,
+// 返回 AUTH_FAIL 或用户不存在的账号会被隔离一段时间
+type UIDPoolManager struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUIDPoolManager(ctx context.Context, svcCtx *svc.ServiceContext) *UIDPoolManager {
+	return &UIDPoolManager{
+		ctx:    ctx,
+		Logger: logx.WithContext(ctx),
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UIDPoolManager) getCacheKey() string {
+	return "bus:uid_pool"
+}
+
+// UIDs 配置中的全部服务账号, BusService.UID 与 BusService.UIDs 合并去重
+func (l *UIDPoolManager) UIDs() []string {
+	conf := l.svcCtx.Config.BusService
+	seen := make(map[string]struct{})
+	var uids []string
+	for _, uid := range append([]string{conf.UID}, conf.UIDs...) {
+		if _, ok := seen[uid]; ok || uid == "" {
+			continue
+		}
+		seen[uid] = struct{}{}
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+// List 获取全部服务账号的使用情况, 顺序与配置一致
+func (l *UIDPoolManager) List() ([]ServiceAccount, error) {
+	uids := l.UIDs()
+	if len(uids) == 0 {
+		return []ServiceAccount{}, nil
+	}
+	raws, err := l.svcCtx.Rdb.HMGet(l.ctx, l.getCacheKey(), uids...).Result()
+	if err != nil {
+		return nil, errors.New("获取校车服务账号失败, redis异常")
+	}
+	accounts := make([]ServiceAccount, len(uids))
+	for i, uid := range uids {
+		accounts[i].UID = uid
+		raw, ok := raws[i].(string)
+		if !ok {
+			continue
+		}
+		if err = json.Unmarshal([]byte(raw), &accounts[i]); err != nil {
+			l.Logger.Errorf("校车服务账号反序列化失败: %v", err)
+			accounts[i] = ServiceAccount{UID: uid}
+		}
+	}
+	return accounts, nil
+}
+
+// Acquire 选取未被隔离且最久未使用的账号, 未配置账号和全部账号被隔离时返回不同的错误
+func (l *UIDPoolManager) Acquire() (string, error) {
+	uids := l.UIDs()
+	if len(uids) == 0 {
+		return "", xerr.WithCode(xerr.ErrBusServiceAccountNotConfigured, "no bus service account configured")
+	}
+	args := make([]interface{}, 0, len(uids)+1)
+	args = append(args, time.Now().UnixMilli())
+	for _, uid := range uids {
+		args = append(args, uid)
+	}
+	uid, err := acquireUIDScript.Run(l.ctx, l.svcCtx.Rdb, []string{l.getCacheKey()}, args...).Text()
+	if errors.Is(err, redis.Nil) {
+		return "", xerr.WithCode(xerr.ErrBusNoServiceAccount, "all bus service accounts are quarantined")
+	}
+	if err != nil {
+		l.Logger.Errorf("选取校车服务账号失败: %v", err)
+		return "", errors.New("选取校车服务账号失败, redis异常")
+	}
+	return uid, nil
+}
+
+// Report 记录账号的请求结果, 账号失效时隔离该账号
+func (l *UIDPoolManager) Report(uid string, err error) {
+	var lastError string
+	var quarantinedUntil int64
+	if err != nil {
+		lastError = err.Error()
+		if IsAccountInvalid(err) {
+			quarantinedUntil = time.Now().Add(l.svcCtx.Config.BusService.UIDQuarantine).UnixMilli()
+			l.Logger.Errorf("校车服务账号 %s 失效, 隔离至 %s: %v", uid,
+				time.UnixMilli(quarantinedUntil).Format("2006-01-02 15:04:05"), err)
+		}
+	}
+	if runErr := reportUIDScript.Run(l.ctx, l.svcCtx.Rdb, []string{l.getCacheKey()}, uid, lastError, quarantinedUntil).Err(); runErr != nil {
+		l.Logger.Errorf("记录校车服务账号请求结果失败: %v", runErr)
+	}
+}
+
+// IsAccountInvalid 账号不存在或刷新 token 后仍鉴权失败, 说明账号本身不可用
+func IsAccountInvalid(err error) bool {
+	var e *xerr.ErrCode
+	if errors.As(err, &e) && (e.Code() == xerr.ErrUserNotFound || e.Code() == xerr.ErrBusTokenInvalid) {
+		return true
+	}
+	return strings.Contains(err.Error(), "AUTH_FAIL") || strings.Contains(err.Error(), "用户不存在")
+}
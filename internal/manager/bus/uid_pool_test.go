@@ -0,0 +1,17 @@
+package bus
+
+import (
+	"errors"
+	"testing"
+
+	"yxy-go/pkg/xerr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAccountInvalid(t *testing.T) {
+	assert.True(t, IsAccountInvalid(xerr.WithCode(xerr.ErrUserNotFound, "用户不存在")))
+	assert.True(t, IsAccountInvalid(xerr.WithCode(xerr.ErrBusTokenInvalid, "yxy response")))
+	assert.True(t, IsAccountInvalid(errors.New(`{"detail":{"code":"AUTH_FAIL"}}`)))
+	assert.False(t, IsAccountInvalid(xerr.WithCode(xerr.ErrHttpClient, "timeout")))
+}
@@ -0,0 +1,121 @@
+package device
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"yxy-go/internal/svc"
+	"yxy-go/internal/utils/yxyClient"
+	"yxy-go/pkg/xerr"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// DeviceProfileManager 管理用户的设备信息, 保证同一用户每次请求易校园时使用相同的设备指纹
+type DeviceProfileManager struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeviceProfileManager(ctx context.Context, svcCtx *svc.ServiceContext) *DeviceProfileManager {
+	return &DeviceProfileManager{
+		ctx:    ctx,
+		Logger: logx.WithContext(ctx),
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DeviceProfileManager) getCacheKey(uid string) string {
+	return "device:profile:" + uid
+}
+
+// GetProfile 获取用户的设备信息
+func (l *DeviceProfileManager) GetProfile(uid string) (*yxyClient.DeviceProfile, error) {
+	raw, err := l.svcCtx.Rdb.Get(l.ctx, l.getCacheKey(uid)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, xerr.WithCode(xerr.ErrDeviceProfileNotFound, fmt.Sprintf("Device profile not found, UID: %v", uid))
+	}
+	if err != nil {
+		return nil, errors.New("获取设备信息失败, redis异常")
+	}
+
+	var profile yxyClient.DeviceProfile
+	if err = json.Unmarshal([]byte(raw), &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// GetOwnProfile 获取本人的设备信息, deviceID 与已保存的不一致时拒绝, 避免仅凭 uid 查看或重置他人的设备信息
+func (l *DeviceProfileManager) GetOwnProfile(uid, deviceID string) (*yxyClient.DeviceProfile, error) {
+	profile, err := l.GetProfile(uid)
+	if err != nil {
+		return nil, err
+	}
+	if deviceID == "" || subtle.ConstantTimeCompare([]byte(deviceID), []byte(profile.DeviceID)) != 1 {
+		return nil, xerr.WithCode(xerr.ErrForbidden, fmt.Sprintf("Device ID mismatch, UID: %v", uid))
+	}
+	return profile, nil
+}
+
+// SaveProfile 保存用户的设备信息, 设备信息不设置过期时间
+func (l *DeviceProfileManager) SaveProfile(uid string, profile *yxyClient.DeviceProfile) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	if err = l.svcCtx.Rdb.Set(l.ctx, l.getCacheKey(uid), data, 0).Err(); err != nil {
+		return errors.New("保存设备信息失败, redis异常")
+	}
+	return nil
+}
+
+// GetOrCreateProfile 优先使用已保存的设备信息, 不存在时根据 deviceID 创建并保存
+func (l *DeviceProfileManager) GetOrCreateProfile(uid, deviceID string) (*yxyClient.DeviceProfile, error) {
+	profile, err := l.GetProfile(uid)
+	if err == nil {
+		if deviceID != "" && deviceID != profile.DeviceID {
+			l.Logger.Infof("%s请求的deviceId与已保存的设备信息不一致, 使用已保存的设备信息", uid)
+		}
+		return profile, nil
+	}
+	if e, ok := err.(*xerr.ErrCode); !ok || e.Code() != xerr.ErrDeviceProfileNotFound || deviceID == "" {
+		return nil, err
+	}
+
+	profile = yxyClient.NewDeviceProfile(deviceID)
+	if err = l.SaveProfile(uid, profile); err != nil {
+		return nil, err
+	}
+	l.Logger.Infof("%s创建设备信息成功", uid)
+	return profile, nil
+}
+
+// ProfileOf 获取用户的设备信息, 未保存时随机生成并保存;
+// 用于电费、校车等不经过本服务登录的请求, 保证同一用户的设备指纹稳定
+func (l *DeviceProfileManager) ProfileOf(uid string) (*yxyClient.DeviceProfile, error) {
+	profile, err := l.GetProfile(uid)
+	var e *xerr.ErrCode
+	if !errors.As(err, &e) || e.Code() != xerr.ErrDeviceProfileNotFound {
+		return profile, err
+	}
+	profile = yxyClient.NewDeviceProfile("")
+	if err = l.SaveProfile(uid, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// ResetProfile 重新生成用户的设备信息, deviceID 为空时随机生成; 重置后需使用新的 deviceID 重新登录
+func (l *DeviceProfileManager) ResetProfile(uid, deviceID string) (*yxyClient.DeviceProfile, error) {
+	profile := yxyClient.NewDeviceProfile(deviceID)
+	if err := l.SaveProfile(uid, profile); err != nil {
+		return nil, err
+	}
+	l.Logger.Infof("%s重置设备信息成功", uid)
+	return profile, nil
+}
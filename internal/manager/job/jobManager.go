@@ -0,0 +1,224 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yxy-go/internal/svc"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/jsonx"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 重叠策略, 上一次执行未结束时的处理方式
+const (
+	OverlapSkip  = "skip"
+	OverlapAllow = "allow"
+)
+
+// 执行状态
+const (
+	StatusRunning  = "running"
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	StatusTimeout  = "timeout"
+	StatusCanceled = "canceled"
+	StatusSkipped  = "skipped"
+)
+
+// 触发方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+const (
+	jobsCacheKey    = "cron:jobs"
+	runningCacheKey = "cron:running"
+	triggerQueueKey = "cron:trigger"
+)
+
+func runsCacheKey(name string) string {
+	return "cron:runs:" + name
+}
+
+// JobInfo 定时任务的注册信息, 由定时服务在启动时写入
+type JobInfo struct {
+	Name     string        `json:"name"`
+	Schedule string        `json:"schedule"`
+	Enabled  bool          `json:"enabled"`
+	Timeout  time.Duration `json:"timeout"`
+	Overlap  string        `json:"overlap"`
+}
+
+// JobRun 定时任务的一次执行记录
+type JobRun struct {
+	Name      string         `json:"name"`
+	Trigger   string         `json:"trigger"`
+	Status    string         `json:"status"`
+	StartedAt int64          `json:"started_at"`
+	EndedAt   int64          `json:"ended_at"`
+	Stats     map[string]int `json:"stats,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// JobManager 保存定时任务的注册信息和执行历史, 定时服务与接口服务通过 redis 共享,
+// 手动触发的任务通过队列交给定时服务执行
+type JobManager struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewJobManager(ctx context.Context, svcCtx *svc.ServiceContext) *JobManager {
+	return &JobManager{
+		ctx:    ctx,
+		Logger: logx.WithContext(ctx),
+		svcCtx: svcCtx,
+	}
+}
+
+// SaveJobs 覆盖保存全部定时任务的注册信息
+func (m *JobManager) SaveJobs(jobs []JobInfo) error {
+	_, err := m.svcCtx.Rdb.TxPipelined(m.ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(m.ctx, jobsCacheKey)
+		for _, job := range jobs {
+			data, err := jsonx.MarshalToString(job)
+			if err != nil {
+				return err
+			}
+			pipe.HSet(m.ctx, jobsCacheKey, job.Name, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.New("保存定时任务失败, redis异常")
+	}
+	return nil
+}
+
+// ListJobs 获取全部定时任务的注册信息
+func (m *JobManager) ListJobs() ([]JobInfo, error) {
+	raws, err := m.svcCtx.Rdb.HGetAll(m.ctx, jobsCacheKey).Result()
+	if err != nil {
+		return nil, errors.New("获取定时任务失败, redis异常")
+	}
+	jobs := make([]JobInfo, 0, len(raws))
+	for _, raw := range raws {
+		var job JobInfo
+		if err = jsonx.UnmarshalFromString(raw, &job); err != nil {
+			m.Logger.Errorf("定时任务反序列化失败: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// GetJob 获取定时任务的注册信息, 不存在时返回 nil
+func (m *JobManager) GetJob(name string) (*JobInfo, error) {
+	raw, err := m.svcCtx.Rdb.HGet(m.ctx, jobsCacheKey, name).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("获取定时任务失败, redis异常")
+	}
+	var job JobInfo
+	if err = jsonx.UnmarshalFromString(raw, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// StartRun 记录正在执行的任务
+func (m *JobManager) StartRun(run JobRun) error {
+	data, err := jsonx.MarshalToString(run)
+	if err != nil {
+		return err
+	}
+	return m.svcCtx.Rdb.HSet(m.ctx, runningCacheKey, run.Name, data).Err()
+}
+
+// FinishRun 保存执行结果, 每个任务只保留最近 Job.HistorySize 条记录
+func (m *JobManager) FinishRun(run JobRun) error {
+	data, err := jsonx.MarshalToString(run)
+	if err != nil {
+		return err
+	}
+	key := runsCacheKey(run.Name)
+	_, err = m.svcCtx.Rdb.TxPipelined(m.ctx, func(pipe redis.Pipeliner) error {
+		if run.Status != StatusSkipped {
+			pipe.HDel(m.ctx, runningCacheKey, run.Name)
+		}
+		pipe.LPush(m.ctx, key, data)
+		pipe.LTrim(m.ctx, key, 0, int64(m.svcCtx.Config.Job.HistorySize-1))
+		return nil
+	})
+	return err
+}
+
+// Running 获取正在执行的任务
+func (m *JobManager) Running() (map[string]JobRun, error) {
+	raws, err := m.svcCtx.Rdb.HGetAll(m.ctx, runningCacheKey).Result()
+	if err != nil {
+		return nil, errors.New("获取定时任务失败, redis异常")
+	}
+	runs := make(map[string]JobRun, len(raws))
+	for name, raw := range raws {
+		var run JobRun
+		if err = jsonx.UnmarshalFromString(raw, &run); err != nil {
+			m.Logger.Errorf("定时任务执行记录反序列化失败: %v", err)
+			continue
+		}
+		runs[name] = run
+	}
+	return runs, nil
+}
+
+// ClearRunning 定时服务启动时清除上次退出前遗留的执行中记录
+func (m *JobManager) ClearRunning() error {
+	return m.svcCtx.Rdb.Del(m.ctx, runningCacheKey).Err()
+}
+
+// History 分页获取任务的执行历史, 按开始时间倒序
+func (m *JobManager) History(name string, page, pageSize int) ([]JobRun, int64, error) {
+	key := runsCacheKey(name)
+	total, err := m.svcCtx.Rdb.LLen(m.ctx, key).Result()
+	if err != nil {
+		return nil, 0, errors.New("获取定时任务执行历史失败, redis异常")
+	}
+	start := int64((page - 1) * pageSize)
+	raws, err := m.svcCtx.Rdb.LRange(m.ctx, key, start, start+int64(pageSize)-1).Result()
+	if err != nil {
+		return nil, 0, errors.New("获取定时任务执行历史失败, redis异常")
+	}
+	runs := make([]JobRun, 0, len(raws))
+	for _, raw := range raws {
+		var run JobRun
+		if err = jsonx.UnmarshalFromString(raw, &run); err != nil {
+			m.Logger.Errorf("定时任务执行记录反序列化失败: %v", err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, total, nil
+}
+
+// Trigger 将任务加入手动触发队列, 由定时服务取出执行
+func (m *JobManager) Trigger(name string) error {
+	if err := m.svcCtx.Rdb.RPush(m.ctx, triggerQueueKey, name).Err(); err != nil {
+		return errors.New("触发定时任务失败, redis异常")
+	}
+	return nil
+}
+
+// PopTrigger 阻塞等待手动触发的任务, 超时时返回 redis.Nil
+func (m *JobManager) PopTrigger(timeout time.Duration) (string, error) {
+	result, err := m.svcCtx.Rdb.BLPop(m.ctx, timeout, triggerQueueKey).Result()
+	if err != nil {
+		return "", err
+	}
+	return result[1], nil
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"yxy-go/pkg/response"
+	"yxy-go/pkg/xerr"
+)
+
+// AdminAuthMiddleware 校验管理接口的 Authorization: Bearer <token>, 未配置 token 时拒绝全部请求
+type AdminAuthMiddleware struct {
+	token string
+}
+
+func NewAdminAuthMiddleware(token string) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{
+		token: token,
+	}
+}
+
+func (m *AdminAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if m.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(m.token)) != 1 {
+			response.HttpResponse(r, w, nil, xerr.WithCode(xerr.ErrForbidden, "admin token mismatch"))
+			return
+		}
+		next(w, r)
+	}
+}
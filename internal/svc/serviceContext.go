@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 	"yxy-go/internal/config"
+	"yxy-go/internal/middleware"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -12,6 +13,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/robfig/cron/v3"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest"
 )
 
 type ServiceContext struct {
@@ -20,6 +22,7 @@ type ServiceContext struct {
 	Rdb         *redis.Client
 	MiniProgram *miniProgram.MiniProgram
 	Cron        *cron.Cron
+	AdminAuth   rest.Middleware
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -29,11 +32,17 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		Rdb:         NewRedis(c),
 		MiniProgram: NewMiniProgram(c),
 		Cron:        NewCron(c),
+		AdminAuth:   middleware.NewAdminAuthMiddleware(c.Admin.Token).Handle,
 	}
 }
 
+// cronEnabled 任意一个提醒定时任务开启时, 需要初始化数据库、小程序和定时任务
+func cronEnabled(c config.Config) bool {
+	return c.LowBattery.EnableCron || c.LowCardBalance.EnableCron || c.AlertRule.EnableCron
+}
+
 func NewGorm(c config.Config) *gorm.DB {
-	if !c.LowBattery.EnableCron {
+	if !cronEnabled(c) {
 		return nil
 	}
 	dsn := fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local", c.Mysql.User, c.Mysql.Pass, c.Mysql.Host, c.Mysql.Port, c.Mysql.DBName)
@@ -44,10 +53,8 @@ func NewGorm(c config.Config) *gorm.DB {
 	return db
 }
 
+// NewRedis 设备信息、校车等接口依赖 redis, 因此不受定时任务开关影响
 func NewRedis(c config.Config) *redis.Client {
-	if !c.LowBattery.EnableCron {
-		return nil
-	}
 	return redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%v:%v", c.Redis.Host, c.Redis.Port),
 		Password: c.Redis.Pass,
@@ -56,7 +63,7 @@ func NewRedis(c config.Config) *redis.Client {
 }
 
 func NewMiniProgram(c config.Config) *miniProgram.MiniProgram {
-	if !c.LowBattery.EnableCron {
+	if !cronEnabled(c) {
 		return nil
 	}
 	mp := c.LowBattery.MiniProgram
@@ -79,7 +86,7 @@ func NewMiniProgram(c config.Config) *miniProgram.MiniProgram {
 }
 
 func NewCron(c config.Config) *cron.Cron {
-	if !c.LowBattery.EnableCron {
+	if !cronEnabled(c) {
 		return nil
 	}
 	loc, _ := time.LoadLocation("Asia/Shanghai")
@@ -3,12 +3,59 @@
 
 package types
 
+type AlertRule struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	Kind      string `json:"kind"`
+	Params    string `json:"params"`
+	Cooldown  int64  `json:"cooldown"`
+	Enabled   bool   `json:"enabled"`
+	CreatedAt string `json:"created_at"`
+}
+
 type BusAnnouncement struct {
-	Title       string   `json:"title"`
-	Author      string   `json:"author"`
-	PublishedAt string   `json:"published_at"`
-	Abstract    string   `json:"abstract"`
-	Content     []string `json:"content"`
+	ID          string                 `json:"id"`
+	Title       string                 `json:"title"`
+	Author      string                 `json:"author"`
+	PublishedAt string                 `json:"published_at"`
+	Abstract    string                 `json:"abstract"`
+	Content     []string               `json:"content"`
+	Blocks      []BusAnnouncementBlock `json:"blocks"`
+	Markdown    string                 `json:"markdown,omitempty"`
+}
+
+type BusAnnouncementBlock struct {
+	Type    string     `json:"type"`
+	Text    string     `json:"text,omitempty"`
+	Level   int        `json:"level,omitempty"`
+	URL     string     `json:"url,omitempty"`
+	Ordered bool       `json:"ordered,omitempty"`
+	Rows    [][]string `json:"rows,omitempty"`
+}
+
+type BusAnnouncementUpdate struct {
+	Type         string          `json:"type"`
+	FirstSeenAt  int64           `json:"first_seen_at"`
+	ChangedAt    int64           `json:"changed_at"`
+	Announcement BusAnnouncement `json:"announcement"`
+}
+
+type BusChangeEvent struct {
+	Type          string `json:"type"`
+	BusID         string `json:"bus_id"`
+	Name          string `json:"name"`
+	DepartureTime string `json:"departure_time,omitempty"`
+	RemainSeats   int    `json:"remain_seats"`
+	OrderedSeats  int    `json:"ordered_seats"`
+	Delta         int    `json:"delta"`
+	Time          int64  `json:"time"`
+}
+
+type BusDateSeats struct {
+	Date          string `json:"date"`
+	DepartureTime string `json:"departure_time"`
+	RemainSeats   int    `json:"remain_seats"`
+	OrderedSeats  int    `json:"ordered_seats"`
 }
 
 type BusInfo struct {
@@ -26,18 +73,198 @@ type BusRecord struct {
 	DepartureTime string `json:"departure_time"`
 }
 
+type BusSchedule struct {
+	ID            string         `json:"id"`
+	DepartureTime string         `json:"departure_time"`
+	Dates         []BusDateSeats `json:"dates"`
+}
+
+type BusSeatPoint struct {
+	Time         int64 `json:"time"`
+	RemainSeats  int   `json:"remain_seats"`
+	OrderedSeats int   `json:"ordered_seats"`
+}
+
+type BusSeatWatch struct {
+	ID            string `json:"id"`
+	BusID         string `json:"bus_id"`
+	DepartureTime string `json:"departure_time"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type BusServiceAccount struct {
+	UID                 string `json:"uid"`
+	Status              string `json:"status"`
+	LastUsedAt          string `json:"last_used_at"`
+	Successes           int64  `json:"successes"`
+	Failures            int64  `json:"failures"`
+	ConsecutiveFailures int64  `json:"consecutive_failures"`
+	QuarantinedUntil    string `json:"quarantined_until"`
+	LastError           string `json:"last_error"`
+}
+
+type BusStation struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Seq  int    `json:"seq"`
+}
+
 type BusTime struct {
 	DepartureTime string `json:"departure_time"`
 	RemainSeats   int    `json:"remain_seats"`
 	OrderedSeats  int    `json:"ordered_seats"`
 }
 
+type BusTimetable struct {
+	RouteID   string        `json:"route_id"`
+	Name      string        `json:"name"`
+	Direction string        `json:"direction"`
+	Price     int           `json:"price"`
+	Stations  []BusStation  `json:"stations"`
+	Schedules []BusSchedule `json:"schedules"`
+}
+
+type CardBalanceAlertSubscription struct {
+	ID        int64   `json:"id"`
+	UserID    int64   `json:"user_id"`
+	DeviceID  string  `json:"device_id"`
+	Threshold float64 `json:"threshold"`
+	Count     int64   `json:"count"`
+}
+
 type CardConsumptionRecord struct {
 	Address string `json:"address"`
 	Money   string `json:"money"`
 	Time    string `json:"time"`
 }
 
+type CardConsumptionSubtotal struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+	Total string `json:"total"`
+}
+
+type CardMealSpending struct {
+	Meal  string `json:"meal"`
+	Total string `json:"total"`
+	Count int    `json:"count"`
+}
+
+type CardMerchantSpending struct {
+	Address  string `json:"address"`
+	Category string `json:"category"`
+	Total    string `json:"total"`
+	Count    int    `json:"count"`
+}
+
+type CardSpendingAnomaly struct {
+	Address string `json:"address"`
+	Money   string `json:"money"`
+	Time    string `json:"time"`
+}
+
+type CardSpendingPeriod struct {
+	Period string `json:"period"`
+	Total  string `json:"total"`
+	Count  int    `json:"count"`
+}
+
+type CardTransaction struct {
+	SerialNo     string `json:"serial_no"`
+	Type         string `json:"type"` // consume|recharge|subsidy|other
+	RawType      string `json:"raw_type"`
+	FeeName      string `json:"fee_name"`
+	Address      string `json:"address"`
+	BusinessName string `json:"business_name"`
+	BusinessNum  string `json:"business_num"`
+	WalletID     string `json:"wallet_id"`
+	Amount       string `json:"amount"`
+	Discount     string `json:"discount"`
+	BalanceAfter string `json:"balance_after"`
+	Time         string `json:"time"`
+	Timestamp    int64  `json:"timestamp"`
+	DealTime     string `json:"deal_time"`
+}
+
+type CardTransactionSummary struct {
+	Type  string `json:"type"`
+	Total string `json:"total"`
+	Count int    `json:"count"`
+}
+
+type CardWallet struct {
+	WalletNo string `json:"wallet_no"`
+	Name     string `json:"name"`
+	Balance  string `json:"balance"`
+	Error    string `json:"error,omitempty"` // 查询该钱包失败时的错误信息
+}
+
+type CreateAlertRuleReq struct {
+	UserID   int64  `json:"user_id"`
+	Kind     string `json:"kind"`
+	Params   string `json:"params,optional"`
+	Cooldown int64  `json:"cooldown,optional"`
+}
+
+type CreateAlertRuleResp struct {
+	Rule AlertRule `json:"rule"`
+}
+
+type CreateBusSeatWatchReq struct {
+	Uid           string `json:"uid"`
+	BusID         string `json:"bus_id"`
+	DepartureTime string `json:"departure_time"`
+}
+
+type CreateBusSeatWatchResp struct {
+	Watch BusSeatWatch `json:"watch"`
+}
+
+type CronJob struct {
+	Name     string      `json:"name"`
+	Schedule string      `json:"schedule"`
+	Enabled  bool        `json:"enabled"`
+	Timeout  string      `json:"timeout"`
+	Overlap  string      `json:"overlap"`
+	Running  bool        `json:"running"`
+	LastRun  *CronJobRun `json:"last_run,omitempty"`
+}
+
+type CronJobRun struct {
+	Name       string         `json:"name"`
+	Trigger    string         `json:"trigger"`
+	Status     string         `json:"status"`
+	StartedAt  string         `json:"started_at"`
+	EndedAt    string         `json:"ended_at"`
+	DurationMs int64          `json:"duration_ms"`
+	Stats      map[string]int `json:"stats,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+type DeleteAlertRuleReq struct {
+	ID int64 `path:"id"`
+}
+
+type DeleteAlertRuleResp struct {
+}
+
+type DeleteBusSeatWatchReq struct {
+	Uid string `form:"uid"`
+	ID  string `path:"id"`
+}
+
+type DeleteBusSeatWatchResp struct {
+}
+
+type DeviceProfile struct {
+	DeviceID   string `json:"device_id"`
+	Brand      string `json:"brand"`
+	MobileType string `json:"mobile_type"`
+	OSVersion  string `json:"os_version"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+}
+
 type ElectricityRechargeRecord struct {
 	Money    string `json:"money"`
 	Datetime string `json:"datetime"`
@@ -48,24 +275,89 @@ type ElectricityUsageRecord struct {
 	Datetime string `json:"datetime"`
 }
 
+type ExportBusReservationReq struct {
+	Uid      string `form:"uid"`
+	Page     int    `form:"page,optional" default:"1"`
+	PageSize int    `form:"page_size,optional" default:"100"`
+	Format   string `form:"format,default=csv,options=csv|xlsx|json|ics"`
+}
+
+type ExportCardConsumptionRecordsReq struct {
+	UID       string `form:"uid"`
+	DeviceID  string `form:"device_id,optional"`
+	Token     string `form:"token,optional"`
+	QueryTime string `form:"query_time,optional"`
+	From      string `form:"from,optional"`
+	To        string `form:"to,optional"`
+	Format    string `form:"format,default=csv,options=csv|xlsx|json"`
+}
+
+type ExportElectricityRechargeRecordsReq struct {
+	Uid           string `form:"uid"`
+	Campus        string `form:"campus,options=zhpf|mgs"`
+	Page          string `form:"page"`
+	RoomStrConcat string `form:"room_str_concat"`
+	Format        string `form:"format,default=csv,options=csv|xlsx|json"`
+}
+
+type ExportElectricityUsageRecordsReq struct {
+	Uid           string `form:"uid"`
+	Campus        string `form:"campus,options=zhpf|mgs"`
+	RoomStrConcat string `form:"room_str_concat"`
+	Format        string `form:"format,default=csv,options=csv|xlsx|json"`
+}
+
+type GetAlertRulesReq struct {
+	UserID int64 `form:"user_id"`
+}
+
+type GetAlertRulesResp struct {
+	List []AlertRule `json:"list"`
+}
+
 type GetBusAnnouncementReq struct {
-	Page     int `form:"page,optional" default:"1"`
-	PageSize int `form:"page_size,optional" default:"10"`
+	Page     int    `form:"page,optional" default:"1"`
+	PageSize int    `form:"page_size,optional" default:"10"`
+	Format   string `form:"format,optional"`
+	Keyword  string `form:"keyword,optional"`
+	Author   string `form:"author,optional"`
+	From     string `form:"from,optional"`
+	To       string `form:"to,optional"`
+	Cursor   string `form:"cursor,optional"`
 }
 
 type GetBusAnnouncementResp struct {
-	UpdatedAt string            `json:"updated_at"`
-	Total     int64             `json:"total"`
-	List      []BusAnnouncement `json:"list"`
+	UpdatedAt  string            `json:"updated_at"`
+	Stale      bool              `json:"stale"`
+	AgeSeconds int64             `json:"age_seconds"`
+	Total      int64             `json:"total"`
+	List       []BusAnnouncement `json:"list"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+type GetBusAnnouncementUpdatesReq struct {
+	Cursor int64 `form:"cursor,optional"`
+	Limit  int   `form:"limit,optional"`
+}
+
+type GetBusAnnouncementUpdatesResp struct {
+	Cursor int64                   `json:"cursor"`
+	List   []BusAnnouncementUpdate `json:"list"`
 }
 
 type GetBusInfoReq struct {
-	Search string `form:"search,optional"`
+	Search       string `form:"search,optional"`
+	ChangedSince int64  `form:"changed_since,optional"`
 }
 
 type GetBusInfoResp struct {
-	UpdatedAt string    `json:"updated_at"`
-	List      []BusInfo `json:"list"`
+	UpdatedAt     string           `json:"updated_at"`
+	Stale         bool             `json:"stale"`
+	AgeSeconds    int64            `json:"age_seconds"`
+	LastChangedAt int64            `json:"last_changed_at"`
+	List          []BusInfo        `json:"list"`
+	Changes       []BusChangeEvent `json:"changes,omitempty"`
+	FullResync    bool             `json:"full_resync,omitempty"`
 }
 
 type GetBusRecordReq struct {
@@ -88,6 +380,48 @@ type GetBusReservationResp struct {
 	List []BusRecord `json:"list"`
 }
 
+type GetBusSeatHistoryReq struct {
+	BusID         string `form:"bus_id"`
+	DepartureTime string `form:"departure_time"`
+}
+
+type GetBusSeatHistoryResp struct {
+	BusID         string         `json:"bus_id"`
+	DepartureTime string         `json:"departure_time"`
+	List          []BusSeatPoint `json:"list"`
+}
+
+type GetBusSeatWatchesReq struct {
+	Uid string `form:"uid"`
+}
+
+type GetBusSeatWatchesResp struct {
+	List []BusSeatWatch `json:"list"`
+}
+
+type GetBusTimetableReq struct {
+	From      string `form:"from,optional"`
+	To        string `form:"to,optional"`
+	Date      string `form:"date,optional"`
+	StartTime string `form:"start_time,optional"`
+	EndTime   string `form:"end_time,optional"`
+}
+
+type GetBusTimetableResp struct {
+	UpdatedAt  string         `json:"updated_at"`
+	Stale      bool           `json:"stale"`
+	AgeSeconds int64          `json:"age_seconds"`
+	List       []BusTimetable `json:"list"`
+}
+
+type GetBusUIDPoolReq struct {
+}
+
+type GetBusUIDPoolResp struct {
+	Available int                 `json:"available"`
+	List      []BusServiceAccount `json:"list"`
+}
+
 type GetCaptchaImageReq struct {
 	DeviceID      string `form:"device_id"`
 	SecurityToken string `form:"security_token"`
@@ -97,25 +431,93 @@ type GetCaptchaImageResp struct {
 	Img string `json:"img"`
 }
 
+type GetCardAnalyticsReq struct {
+	UID      string `form:"uid"`
+	DeviceID string `form:"device_id,optional"`
+	Token    string `form:"token,optional"`
+	From     string `form:"from,optional"`
+	To       string `form:"to,optional"`
+}
+
+type GetCardAnalyticsResp struct {
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	Total         string                 `json:"total"`
+	Count         int                    `json:"count"`
+	AverageTicket string                 `json:"average_ticket"`
+	Daily         []CardSpendingPeriod   `json:"daily"`
+	Weekly        []CardSpendingPeriod   `json:"weekly"`
+	Monthly       []CardSpendingPeriod   `json:"monthly"`
+	Merchants     []CardMerchantSpending `json:"merchants"`
+	Meals         []CardMealSpending     `json:"meals"`
+	Anomalies     []CardSpendingAnomaly  `json:"anomalies"`
+}
+
 type GetCardBalanceReq struct {
 	UID      string `form:"uid"`
-	DeviceID string `form:"device_id"`
+	DeviceID string `form:"device_id,optional"`
 	Token    string `form:"token,optional"`
+	WalletNo string `form:"wallet_no,optional"`
 }
 
 type GetCardBalanceResp struct {
-	Balance string `json:"balance"`
+	Balance string       `json:"balance"`
+	Wallets []CardWallet `json:"wallets"`
 }
 
 type GetCardConsumptionRecordsReq struct {
 	UID       string `form:"uid"`
-	DeviceID  string `form:"device_id"`
+	DeviceID  string `form:"device_id,optional"`
 	Token     string `form:"token,optional"`
-	QueryTime string `form:"query_time"`
+	QueryTime string `form:"query_time,optional"`
+	From      string `form:"from,optional"`
+	To        string `form:"to,optional"`
 }
 
 type GetCardConsumptionRecordsResp struct {
-	List []CardConsumptionRecord `json:"list"`
+	List      []CardConsumptionRecord   `json:"list"`
+	Subtotals []CardConsumptionSubtotal `json:"subtotals"`
+}
+
+type GetCardTransactionsReq struct {
+	UID       string `form:"uid"`
+	DeviceID  string `form:"device_id,optional"`
+	Token     string `form:"token,optional"`
+	QueryTime string `form:"query_time,optional"`
+	From      string `form:"from,optional"`
+	To        string `form:"to,optional"`
+}
+
+type GetCardTransactionsResp struct {
+	List    []CardTransaction        `json:"list"`
+	Summary []CardTransactionSummary `json:"summary"`
+}
+
+type GetCronJobRunsReq struct {
+	Name     string `path:"name"`
+	Page     int    `form:"page,optional" default:"1"`
+	PageSize int    `form:"page_size,optional" default:"20"`
+}
+
+type GetCronJobRunsResp struct {
+	Total int64        `json:"total"`
+	List  []CronJobRun `json:"list"`
+}
+
+type GetCronJobsReq struct {
+}
+
+type GetCronJobsResp struct {
+	List []CronJob `json:"list"`
+}
+
+type GetDeviceProfileReq struct {
+	UID      string `form:"uid"`
+	DeviceID string `form:"device_id"` // 登录时使用的 device_id, 用于确认是本人
+}
+
+type GetDeviceProfileResp struct {
+	Profile DeviceProfile `json:"profile"`
 }
 
 type GetElectricityRechargeRecordsReq struct {
@@ -173,7 +575,7 @@ type LoginByCodeResp struct {
 
 type LoginBySilentReq struct {
 	UID      string `json:"uid"`
-	DeviceID string `json:"device_id"`
+	DeviceID string `json:"device_id,optional"`
 	PhoneNum string `json:"phone_num,optional"`
 	Token    string `json:"token,optional"`
 }
@@ -182,6 +584,27 @@ type LoginBySilentResp struct {
 	Token string `json:"token"`
 }
 
+type ResetDeviceProfileReq struct {
+	UID         string `json:"uid"`
+	DeviceID    string `json:"device_id"`              // 当前设备信息的 device_id, 用于确认是本人
+	NewDeviceID string `json:"new_device_id,optional"` // 为空时随机生成
+}
+
+type ResetDeviceProfileResp struct {
+	Profile DeviceProfile `json:"profile"`
+}
+
+type SaveCardBalanceAlertSubscriptionReq struct {
+	UserID    int64   `json:"user_id"`
+	DeviceID  string  `json:"device_id,optional"`
+	Threshold float64 `json:"threshold"`
+	Count     int64   `json:"count"`
+}
+
+type SaveCardBalanceAlertSubscriptionResp struct {
+	Subscription CardBalanceAlertSubscription `json:"subscription"`
+}
+
 type SendCodeReq struct {
 	DeviceID      string `json:"device_id"`
 	SecurityToken string `json:"security_token"`
@@ -192,3 +615,24 @@ type SendCodeReq struct {
 type SendCodeResp struct {
 	UserExists bool `json:"user_exists"`
 }
+
+type SubscribeBusAnnouncementReq struct {
+	Uid string `json:"uid"`
+}
+
+type SubscribeBusAnnouncementResp struct {
+}
+
+type TriggerCronJobReq struct {
+	Name string `path:"name"`
+}
+
+type TriggerCronJobResp struct {
+}
+
+type UnsubscribeBusAnnouncementReq struct {
+	Uid string `form:"uid"`
+}
+
+type UnsubscribeBusAnnouncementResp struct {
+}
@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event 日历事件
+type Event struct {
+	UID      string
+	Summary  string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// ExportCalendar 导出 iCalendar(RFC 5545) 格式的日历
+func ExportCalendar(name, calName string, events []Event) *File {
+	return NewFile(FormatICS, name, func(w io.Writer) error {
+		return writeCalendar(w, calName, events)
+	})
+}
+
+func writeCalendar(w io.Writer, calName string, events []Event) error {
+	b := bufio.NewWriter(w)
+	writeLine := func(line string) {
+		b.WriteString(foldLine(line))
+		b.WriteString("\r\n")
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//yxy-go//yxy-api//CN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("X-WR-CALNAME:" + escapeText(calName))
+	for _, e := range events {
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + e.UID)
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART:" + e.Start.UTC().Format("20060102T150405Z"))
+		writeLine("DTEND:" + e.Start.Add(e.Duration).UTC().Format("20060102T150405Z"))
+		writeLine("SUMMARY:" + escapeText(e.Summary))
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+	// bufio.Writer 出错后不再写入, 只需在 Flush 时检查错误
+	return b.Flush()
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldLine 单行超过75字节时折行, 不拆分多字节字符
+func foldLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range line {
+		size := len(string(r))
+		if n+size > limit {
+			b.WriteString("\r\n ")
+			// 续行开头的空格占用1字节
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
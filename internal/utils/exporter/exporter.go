@@ -0,0 +1,115 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"yxy-go/pkg/xerr"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatJSON = "json"
+	FormatICS  = "ics"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatJSON: "application/json; charset=utf-8",
+	FormatICS:  "text/calendar; charset=utf-8",
+}
+
+// File 导出的文件, 内容在写入响应时才编码, 不在内存中缓存整个文件
+type File struct {
+	Name        string
+	ContentType string
+	write       func(w io.Writer) error
+}
+
+// Write 将文件内容编码写入 w
+func (f *File) Write(w io.Writer) error {
+	return f.write(w)
+}
+
+// Table 表格数据, 用于导出 csv 和 xlsx
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// ExportTable 按格式导出表格, json 格式直接序列化 list
+func ExportTable(format, name string, table Table, list any) (*File, error) {
+	var write func(w io.Writer) error
+	switch format {
+	case FormatCSV:
+		write = func(w io.Writer) error { return encodeCSV(w, table) }
+	case FormatXLSX:
+		write = func(w io.Writer) error { return encodeXLSX(w, table) }
+	case FormatJSON:
+		write = func(w io.Writer) error { return json.NewEncoder(w).Encode(list) }
+	default:
+		return nil, xerr.WithCode(xerr.ErrParam, fmt.Sprintf("unsupported export format: %v", format))
+	}
+	return NewFile(format, name, write), nil
+}
+
+// NewFile 根据格式补全文件名后缀和 Content-Type
+func NewFile(format, name string, write func(w io.Writer) error) *File {
+	return &File{
+		Name:        name + "." + format,
+		ContentType: contentTypes[format],
+		write:       write,
+	}
+}
+
+func encodeCSV(w io.Writer, table Table) error {
+	// 写入 BOM, 避免 Excel 打开中文乱码
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(table.Headers); err != nil {
+		return err
+	}
+	return cw.WriteAll(table.Rows)
+}
+
+func encodeXLSX(w io.Writer, table Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	if err = sw.SetRow("A1", toCells(table.Headers)); err != nil {
+		return err
+	}
+	for i, row := range table.Rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err = sw.SetRow(cell, toCells(row)); err != nil {
+			return err
+		}
+	}
+	if err = sw.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+func toCells(row []string) []any {
+	cells := make([]any, len(row))
+	for i, v := range row {
+		cells[i] = v
+	}
+	return cells
+}
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"yxy-go/pkg/xerr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportTable(t *testing.T) {
+	table := Table{
+		Headers: []string{"消费时间", "消费地点", "金额"},
+		Rows:    [][]string{{"2024-09-02 11:45:00", "朝晖一食堂, 二楼", "-12.50"}},
+	}
+
+	var buf bytes.Buffer
+	file, err := ExportTable(FormatCSV, "records", table, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "records.csv", file.Name)
+	assert.NoError(t, file.Write(&buf))
+	assert.Equal(t, "\xEF\xBB\xBF消费时间,消费地点,金额\n2024-09-02 11:45:00,\"朝晖一食堂, 二楼\",-12.50\n", buf.String())
+
+	buf.Reset()
+	file, err = ExportTable(FormatXLSX, "records", table, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "records.xlsx", file.Name)
+	assert.NoError(t, file.Write(&buf))
+	assert.True(t, strings.HasPrefix(buf.String(), "PK"))
+
+	_, err = ExportTable("pdf", "records", table, nil)
+	var e *xerr.ErrCode
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, xerr.ErrParam, e.Code())
+	}
+}
+
+func TestExportCalendar(t *testing.T) {
+	start := time.Date(2025, 3, 3, 7, 30, 0, 0, time.FixedZone("CST", 8*3600))
+	file := ExportCalendar("bus", "校车预约", []Event{{
+		UID:      "1@yxy-go",
+		Summary:  "校车 朝晖-屏峰, 直达",
+		Start:    start,
+		Duration: time.Hour,
+	}})
+
+	var buf bytes.Buffer
+	assert.NoError(t, file.Write(&buf))
+	ics := buf.String()
+	assert.Equal(t, "bus.ics", file.Name)
+	assert.Contains(t, ics, "DTSTART:20250302T233000Z\r\n")
+	assert.Contains(t, ics, "DTEND:20250303T003000Z\r\n")
+	assert.Contains(t, ics, `SUMMARY:校车 朝晖-屏峰\, 直达`)
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("校车", 20)
+	for _, l := range strings.Split(foldLine(line), "\r\n") {
+		assert.LessOrEqual(t, len(l), 75)
+	}
+	assert.Equal(t, line, strings.ReplaceAll(foldLine(line), "\r\n ", ""))
+}
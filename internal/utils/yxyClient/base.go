@@ -1,7 +1,6 @@
 package yxyClient
 
 import (
-	"fmt"
 	"strings"
 	"time"
 	"yxy-go/internal/consts"
@@ -29,15 +28,18 @@ func GetYxyBaseReqParam(deviceID string) (baseReq map[string]interface{}, baseHe
 		"nt":         time.Now().UnixMilli(),
 	}
 	baseHeaders = map[string]string{
-		"User-Agent": fmt.Sprintf("Mozilla/5.0 (Linux; Android 12; Android for arm64; wv) "+
-			"AppleWebKit/537.36 (KHTML, like Gecko) "+
-			"Version/4.0 Chrome/126.0.6478.186 Mobile Safari/537.36 "+
-			"ZJYXYwebviewbroswer ZJYXYAndroid "+
-			"tourCustomer/yunmaapp.NET/%v/%v", consts.APP_ALL_VERSION, deviceID),
+		"User-Agent": GenYxyUserAgent("12", "Android for arm64", deviceID),
 	}
 	return baseReq, baseHeaders
 }
 
+// GetYxyBaseReqParamByProfile 使用指定设备信息构造基础请求参数
+func GetYxyBaseReqParamByProfile(profile *DeviceProfile) (baseReq map[string]interface{}, baseHeaders map[string]string) {
+	baseReq, baseHeaders = GetYxyBaseReqParam(profile.DeviceID)
+	baseHeaders["User-Agent"] = profile.UserAgent
+	return baseReq, baseHeaders
+}
+
 type YxyBusErrorResp struct {
 	Detail struct {
 		Code string `json:"code"`
@@ -0,0 +1,65 @@
+package yxyClient
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+	"yxy-go/internal/consts"
+
+	"github.com/google/uuid"
+)
+
+// DeviceProfile 模拟的设备信息, 同一用户需保持稳定, 否则易校园会以 deviceId changed 为由登出账号
+type DeviceProfile struct {
+	DeviceID   string `json:"device_id"`
+	Brand      string `json:"brand"`
+	MobileType string `json:"mobile_type"`
+	OSVersion  string `json:"os_version"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// deviceModels 常见安卓机型, 用于生成设备信息
+var deviceModels = []struct {
+	Brand      string
+	MobileType string
+	OSVersion  string
+}{
+	{"Redmi", "23013RK75C", "15"},
+	{"Xiaomi", "2211133C", "14"},
+	{"HUAWEI", "NOH-AN00", "12"},
+	{"HONOR", "FNE-AN00", "13"},
+	{"OPPO", "PGFM10", "14"},
+	{"vivo", "V2241A", "14"},
+	{"OnePlus", "PHB110", "14"},
+}
+
+// NewDeviceProfile 根据 deviceID 生成设备信息, 同一 deviceID 总是得到相同的机型; deviceID 为空时随机生成
+func NewDeviceProfile(deviceID string) *DeviceProfile {
+	if deviceID == "" {
+		deviceID = strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(deviceID))
+	model := deviceModels[h.Sum32()%uint32(len(deviceModels))]
+
+	return &DeviceProfile{
+		DeviceID:   deviceID,
+		Brand:      model.Brand,
+		MobileType: model.MobileType,
+		OSVersion:  model.OSVersion,
+		UserAgent:  GenYxyUserAgent(model.OSVersion, model.MobileType, GenYxyDeviceID(deviceID)),
+		CreatedAt:  time.Now().UnixMilli(),
+	}
+}
+
+// GenYxyUserAgent 生成易校园 APP 内置浏览器的 User-Agent
+func GenYxyUserAgent(osVersion, mobileType, yxyDeviceID string) string {
+	return fmt.Sprintf("Mozilla/5.0 (Linux; Android %v; %v; wv) "+
+		"AppleWebKit/537.36 (KHTML, like Gecko) "+
+		"Version/4.0 Chrome/126.0.6478.186 Mobile Safari/537.36 "+
+		"ZJYXYwebviewbroswer ZJYXYAndroid "+
+		"tourCustomer/yunmaapp.NET/%v/%v", osVersion, mobileType, consts.APP_ALL_VERSION, yxyDeviceID)
+}
@@ -0,0 +1,24 @@
+package yxyClient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDeviceProfile(t *testing.T) {
+	deviceID := "bcbcbb20801444c96978b0c72f114514"
+	p1 := NewDeviceProfile(deviceID)
+	p2 := NewDeviceProfile(deviceID)
+	assert.Equal(t, deviceID, p1.DeviceID)
+	assert.Equal(t, p1.Brand, p2.Brand)
+	assert.Equal(t, p1.MobileType, p2.MobileType)
+	assert.Equal(t, p1.UserAgent, p2.UserAgent)
+	assert.Contains(t, p1.UserAgent, "Android "+p1.OSVersion+"; "+p1.MobileType+";")
+	assert.True(t, strings.HasSuffix(p1.UserAgent, "/ym-"+deviceID))
+
+	random := NewDeviceProfile("")
+	assert.Len(t, random.DeviceID, 32)
+	assert.NotContains(t, random.DeviceID, "-")
+}
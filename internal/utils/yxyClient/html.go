@@ -1,9 +1,13 @@
 package yxyClient
 
 import (
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 // ParseHTMLAnnouncement 解析 HTML 公告，处理 p 标签换行
@@ -24,3 +28,291 @@ func ParseHTMLAnnouncement(htmlContent string) []string {
 	})
 	return result
 }
+
+// 公告内容块类型
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockImage     = "image"
+	BlockLink      = "link"
+	BlockListItem  = "list_item"
+	BlockTable     = "table"
+)
+
+// AnnouncementBlock 公告中的一个内容块, 按 Type 使用对应字段
+type AnnouncementBlock struct {
+	Type    string     `json:"type"`
+	Text    string     `json:"text,omitempty"`    // 段落、标题、列表项、链接文字, 图片的 alt
+	Level   int        `json:"level,omitempty"`   // 标题级别 1-6
+	URL     string     `json:"url,omitempty"`     // 图片、链接地址
+	Ordered bool       `json:"ordered,omitempty"` // 是否为有序列表项
+	Rows    [][]string `json:"rows,omitempty"`    // 表格, 第一行为表头
+}
+
+// ParseHTMLBlocks 将 HTML 公告解析为结构化的内容块, 保留图片、链接、列表、表格和段落内换行
+func ParseHTMLBlocks(htmlContent string) []AnnouncementBlock {
+	root, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return []AnnouncementBlock{}
+	}
+	p := &blockParser{blocks: make([]AnnouncementBlock, 0)}
+	p.walk(root)
+	p.flush()
+	return p.blocks
+}
+
+type blockParser struct {
+	blocks []AnnouncementBlock
+	text   strings.Builder
+	// 段落中的链接, 在段落之后输出
+	links []AnnouncementBlock
+}
+
+func (p *blockParser) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		p.text.WriteString(collapseSpace(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		p.walkChildren(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style, atom.Head:
+	case atom.Br:
+		p.text.WriteString("\n")
+	case atom.Img:
+		p.flush()
+		if src := safeURL(attr(n, "src")); src != "" {
+			p.blocks = append(p.blocks, AnnouncementBlock{Type: BlockImage, Text: attr(n, "alt"), URL: src})
+		}
+	case atom.A:
+		p.walkChildren(n)
+		if href := safeURL(attr(n, "href")); href != "" {
+			p.links = append(p.links, AnnouncementBlock{Type: BlockLink, Text: textOf(n), URL: href})
+		}
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		p.flush()
+		if text := textOf(n); text != "" {
+			p.blocks = append(p.blocks, AnnouncementBlock{Type: BlockHeading, Text: text, Level: int(n.Data[1] - '0')})
+		}
+	case atom.Ul, atom.Ol:
+		p.flush()
+		for li := n.FirstChild; li != nil; li = li.NextSibling {
+			if li.DataAtom != atom.Li {
+				continue
+			}
+			// 列表项中的文字输出为列表项, 图片和链接照常输出
+			for _, block := range subBlocks(li) {
+				if block.Type == BlockParagraph {
+					block.Type, block.Ordered = BlockListItem, n.DataAtom == atom.Ol
+				}
+				p.blocks = append(p.blocks, block)
+			}
+		}
+	case atom.Table:
+		p.flush()
+		if rows := tableRows(n); len(rows) > 0 {
+			p.blocks = append(p.blocks, AnnouncementBlock{Type: BlockTable, Rows: rows})
+		}
+		// 单元格中的图片和链接在表格之后输出
+		for _, block := range subBlocks(n) {
+			if block.Type == BlockImage || block.Type == BlockLink {
+				p.blocks = append(p.blocks, block)
+			}
+		}
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Blockquote, atom.Pre, atom.Center:
+		p.flush()
+		p.walkChildren(n)
+		p.flush()
+	default:
+		p.walkChildren(n)
+	}
+}
+
+func (p *blockParser) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.walk(c)
+	}
+}
+
+// subBlocks 解析节点的子节点为内容块
+func subBlocks(n *html.Node) []AnnouncementBlock {
+	sub := &blockParser{}
+	sub.walkChildren(n)
+	sub.flush()
+	return sub.blocks
+}
+
+// flush 将已累积的文字输出为段落, 并输出段落中的链接
+func (p *blockParser) flush() {
+	if text := cleanLines(p.text.String()); text != "" {
+		p.blocks = append(p.blocks, AnnouncementBlock{Type: BlockParagraph, Text: text})
+	}
+	p.blocks = append(p.blocks, p.links...)
+	p.text.Reset()
+	p.links = nil
+}
+
+// textOf 获取节点的文字, br 转为换行
+func textOf(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(collapseSpace(n.Data))
+		case n.DataAtom == atom.Br:
+			b.WriteString("\n")
+		case n.DataAtom == atom.Script || n.DataAtom == atom.Style:
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return cleanLines(b.String())
+}
+
+func tableRows(table *html.Node) [][]string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.DataAtom == atom.Tr {
+			var row []string
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.DataAtom == atom.Td || c.DataAtom == atom.Th {
+					row = append(row, textOf(c))
+				}
+			}
+			if len(row) > 0 {
+				rows = append(rows, row)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(table)
+	return rows
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+// safeURL 只保留 http(s) 地址, 过滤 javascript: 等其他协议
+func safeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ""
+	}
+	return raw
+}
+
+// collapseSpace 将连续空白(包括 &nbsp;)合并为一个空格, 首尾空白由 cleanLines 去除
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' || r == '\u00a0' {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// cleanLines 去除每行首尾空白和空行
+func cleanLines(s string) string {
+	lines := strings.Split(s, "\n")
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// BlocksToMarkdown 将内容块转为 Markdown
+func BlocksToMarkdown(blocks []AnnouncementBlock) string {
+	var b strings.Builder
+	orderedIndex := 0
+	for i, block := range blocks {
+		if i > 0 {
+			// 连续的列表项之间只换一行
+			if block.Type == BlockListItem && blocks[i-1].Type == BlockListItem {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		if block.Type != BlockListItem || !block.Ordered {
+			orderedIndex = 0
+		}
+		switch block.Type {
+		case BlockHeading:
+			b.WriteString(strings.Repeat("#", block.Level) + " " + strings.ReplaceAll(block.Text, "\n", " "))
+		case BlockImage:
+			b.WriteString("![" + block.Text + "](" + block.URL + ")")
+		case BlockLink:
+			b.WriteString("[" + block.Text + "](" + block.URL + ")")
+		case BlockListItem:
+			prefix := "- "
+			if block.Ordered {
+				orderedIndex++
+				prefix = strconv.Itoa(orderedIndex) + ". "
+			}
+			b.WriteString(prefix + strings.ReplaceAll(block.Text, "\n", "  \n  "))
+		case BlockTable:
+			writeMarkdownTable(&b, block.Rows)
+		default:
+			// 段落内换行使用 Markdown 的硬换行
+			b.WriteString(strings.ReplaceAll(block.Text, "\n", "  \n"))
+		}
+	}
+	return b.String()
+}
+
+func writeMarkdownTable(b *strings.Builder, rows [][]string) {
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	writeRow := func(row []string) {
+		cells := make([]string, cols)
+		for i := range cells {
+			if i < len(row) {
+				cells[i] = strings.NewReplacer("|", `\|`, "\n", "<br>").Replace(row[i])
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |")
+	}
+	for i, row := range rows {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeRow(row)
+		if i == 0 {
+			b.WriteString("\n|" + strings.Repeat(" --- |", cols))
+		}
+	}
+}
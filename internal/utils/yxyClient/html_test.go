@@ -0,0 +1,53 @@
+package yxyClient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAnnouncementHTML = `<h2>国庆 停运通知</h2>
+<p>各位师生:<br>10月1日至7日校车停运,&nbsp;详见<a href="https://example.com/notice">通知原文</a>。</p>
+<p><img src="https://example.com/timetable.png" alt="时刻表"></p>
+<ol><li>朝晖 → 屏峰</li><li>屏峰 → 朝晖</li></ol>
+<table><tr><th>班次</th><th>时间</th></tr><tr><td>1</td><td>07:30</td></tr></table>`
+
+func TestParseHTMLBlocks(t *testing.T) {
+	blocks := ParseHTMLBlocks(testAnnouncementHTML)
+	assert.Equal(t, []AnnouncementBlock{
+		{Type: BlockHeading, Text: "国庆 停运通知", Level: 2},
+		{Type: BlockParagraph, Text: "各位师生:\n10月1日至7日校车停运, 详见通知原文。"},
+		{Type: BlockLink, Text: "通知原文", URL: "https://example.com/notice"},
+		{Type: BlockImage, Text: "时刻表", URL: "https://example.com/timetable.png"},
+		{Type: BlockListItem, Text: "朝晖 → 屏峰", Ordered: true},
+		{Type: BlockListItem, Text: "屏峰 → 朝晖", Ordered: true},
+		{Type: BlockTable, Rows: [][]string{{"班次", "时间"}, {"1", "07:30"}}},
+	}, blocks)
+
+	// 原有的纯文本解析保持不变
+	assert.Equal(t, []string{"各位师生:10月1日至7日校车停运, 详见通知原文。"}, ParseHTMLAnnouncement(testAnnouncementHTML))
+}
+
+func TestBlocksToMarkdown(t *testing.T) {
+	markdown := BlocksToMarkdown(ParseHTMLBlocks(testAnnouncementHTML))
+	assert.Equal(t, "## 国庆 停运通知\n\n"+
+		"各位师生:  \n10月1日至7日校车停运, 详见通知原文。\n\n"+
+		"[通知原文](https://example.com/notice)\n\n"+
+		"![时刻表](https://example.com/timetable.png)\n\n"+
+		"1. 朝晖 → 屏峰\n2. 屏峰 → 朝晖\n\n"+
+		"| 班次 | 时间 |\n| --- | --- |\n| 1 | 07:30 |", markdown)
+}
+
+func TestParseHTMLBlocksNested(t *testing.T) {
+	blocks := ParseHTMLBlocks(`<ul><li>详见<a href="https://example.com/a">附件</a></li><li><img src="http://example.com/b.png" alt="线路图"></li></ul>` +
+		`<table><tr><td><a href="https://example.com/c">时刻表</a></td></tr></table>` +
+		`<p><a href="javascript:alert(1)">点击</a><img src="data:image/png;base64,AAAA"></p>`)
+	assert.Equal(t, []AnnouncementBlock{
+		{Type: BlockListItem, Text: "详见附件"},
+		{Type: BlockLink, Text: "附件", URL: "https://example.com/a"},
+		{Type: BlockImage, Text: "线路图", URL: "http://example.com/b.png"},
+		{Type: BlockTable, Rows: [][]string{{"时刻表"}}},
+		{Type: BlockLink, Text: "时刻表", URL: "https://example.com/c"},
+		{Type: BlockParagraph, Text: "点击"},
+	}, blocks)
+}
@@ -1,6 +1,7 @@
 package yxyClient
 
 import (
+	"context"
 	urllib "net/url"
 	"sync"
 	"yxy-go/pkg/xerr"
@@ -25,6 +26,11 @@ func GetClient() *resty.Client {
 }
 
 func HttpSendPost(url string, req map[string]interface{}, headers map[string]string, resp interface{}) (*resty.Response, error) {
+	return HttpSendPostWithContext(context.Background(), url, req, headers, resp)
+}
+
+// HttpSendPostWithContext 同 HttpSendPost, ctx 取消或超时时中断请求
+func HttpSendPostWithContext(ctx context.Context, url string, req map[string]interface{}, headers map[string]string, resp interface{}) (*resty.Response, error) {
 	client := GetClient()
 	parsedURL, err := urllib.Parse(url)
 	if err != nil {
@@ -36,6 +42,7 @@ func HttpSendPost(url string, req map[string]interface{}, headers map[string]str
 		headers["sign"] = sign
 	}
 	r, err := client.R().
+		SetContext(ctx).
 		SetHeaders(headers).
 		SetBody(req).
 		SetResult(&resp).
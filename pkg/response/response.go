@@ -1,6 +1,8 @@
 package response
 
 import (
+	"io"
+	"mime"
 	"net/http"
 	"yxy-go/pkg/xerr"
 
@@ -35,6 +37,18 @@ func HttpResponse(r *http.Request, w http.ResponseWriter, resp interface{}, err
 	}
 }
 
+// FileResponse 以附件形式返回文件, 文件内容由 write 直接写入响应
+func FileResponse(r *http.Request, w http.ResponseWriter, name, contentType string, write func(w io.Writer) error) {
+	logc.Infof(r.Context(), "[HTTP] %d - %s %s - %s - %s", http.StatusOK, r.Method, r.RequestURI, r.RemoteAddr, r.UserAgent())
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	w.WriteHeader(http.StatusOK)
+	// 响应头已发送, 写入失败时只能记录日志
+	if err := write(w); err != nil {
+		logc.Errorf(r.Context(), "[HTTP] write file %s failed: %v", name, err)
+	}
+}
+
 func ParamErrorResponse(r *http.Request, w http.ResponseWriter, err error) {
 	logc.Infof(r.Context(), "[HTTP] %d - %s %s - %v - %s - %s", http.StatusOK, r.Method, r.RequestURI, err, r.RemoteAddr, r.UserAgent())
 	httpx.WriteJson(w, http.StatusOK, Error(xerr.ErrParam))
@@ -9,16 +9,19 @@ const ErrSuccess Code = 0 // Success
 
 // common err
 const (
-	ErrUnknown    Code = iota + 100001 // 服务异常
-	ErrParam                           // 参数错误
-	ErrHttpClient                      // HTTP客户端请求错误
+	ErrUnknown      Code = iota + 100001 // 服务异常
+	ErrParam                             // 参数错误
+	ErrHttpClient                        // HTTP客户端请求错误
+	ErrForbidden                         // 无权访问
+	ErrDataNotReady                      // 数据暂未就绪, 请稍后重试
 )
 
 // yxy common err
 const (
-	ErrUserNotFound     Code = iota + 100101 // 用户不存在
-	ErrAccountLoggedOut                      // 账号被登出
-	ErrNotBindCard                           // 用户还未绑卡
+	ErrUserNotFound          Code = iota + 100101 // 用户不存在
+	ErrAccountLoggedOut                           // 账号被登出
+	ErrNotBindCard                                // 用户还未绑卡
+	ErrDeviceProfileNotFound                      // 设备信息不存在, 请重新登录
 )
 
 // login err
@@ -42,5 +45,19 @@ const (
 
 // bus err
 const (
-	ErrBusTokenInvalid Code = iota + 110201 // 校车Token无效
+	ErrBusTokenInvalid                Code = iota + 110201 // 校车Token无效
+	ErrBusSeatWatchLimit                                   // 余票提醒数量已达上限
+	ErrBusSeatWatchNotFound                                // 余票提醒不存在
+	ErrBusNoServiceAccount                                 // 暂无可用的校车服务账号
+	ErrBusServiceAccountNotConfigured                      // 未配置校车服务账号
+)
+
+// job err
+const (
+	ErrJobNotFound Code = iota + 120001 // 定时任务不存在
+)
+
+// alert err
+const (
+	ErrAlertRuleNotFound Code = iota + 120101 // 提醒规则不存在
 )
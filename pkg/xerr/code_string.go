@@ -12,9 +12,12 @@ func _() {
 	_ = x[ErrUnknown-100001]
 	_ = x[ErrParam-100002]
 	_ = x[ErrHttpClient-100003]
+	_ = x[ErrForbidden-100004]
+	_ = x[ErrDataNotReady-100005]
 	_ = x[ErrUserNotFound-100101]
 	_ = x[ErrAccountLoggedOut-100102]
 	_ = x[ErrNotBindCard-100103]
+	_ = x[ErrDeviceProfileNotFound-100104]
 	_ = x[ErrTokenInvalid-110001]
 	_ = x[ErrCaptchaInvalid-110002]
 	_ = x[ErrCaptchaWrong-110003]
@@ -27,32 +30,41 @@ func _() {
 	_ = x[ErrElectricityBindNotFound-110102]
 	_ = x[ErrRoomInfoWrongOrCampusMismatch-110103]
 	_ = x[ErrBusTokenInvalid-110201]
+	_ = x[ErrBusSeatWatchLimit-110202]
+	_ = x[ErrBusSeatWatchNotFound-110203]
+	_ = x[ErrBusNoServiceAccount-110204]
+	_ = x[ErrBusServiceAccountNotConfigured-110205]
+	_ = x[ErrJobNotFound-120001]
+	_ = x[ErrAlertRuleNotFound-120101]
 }
 
 const (
 	_Code_name_0 = "Success"
-	_Code_name_1 = "服务异常参数错误HTTP客户端请求错误"
-	_Code_name_2 = "用户不存在账号被登出用户还未绑卡"
+	_Code_name_1 = "服务异常参数错误HTTP客户端请求错误无权访问数据暂未就绪, 请稍后重试"
+	_Code_name_2 = "用户不存在账号被登出用户还未绑卡设备信息不存在, 请重新登录"
 	_Code_name_3 = "Token无效图片验证码已失效图片验证码错误deviceId不一致手机号格式错误短信发送超限手机验证码错误, 错误3次将锁定15分钟手机验证码错误3次, 账号锁定15分钟"
 	_Code_name_4 = "电费Token无效未找到电费绑定信息房间信息有误或校区不匹配"
-	_Code_name_5 = "校车Token无效"
+	_Code_name_5 = "校车Token无效余票提醒数量已达上限余票提醒不存在暂无可用的校车服务账号未配置校车服务账号"
+	_Code_name_6 = "定时任务不存在"
+	_Code_name_7 = "提醒规则不存在"
 )
 
 var (
-	_Code_index_1 = [...]uint8{0, 12, 24, 49}
-	_Code_index_2 = [...]uint8{0, 15, 30, 48}
+	_Code_index_1 = [...]uint8{0, 12, 24, 49, 61, 96}
+	_Code_index_2 = [...]uint8{0, 15, 30, 48, 86}
 	_Code_index_3 = [...]uint8{0, 11, 35, 56, 73, 94, 112, 162, 209}
 	_Code_index_4 = [...]uint8{0, 17, 44, 80}
+	_Code_index_5 = [...]uint8{0, 17, 47, 68, 101, 128}
 )
 
 func (i Code) String() string {
 	switch {
 	case i == 0:
 		return _Code_name_0
-	case 100001 <= i && i <= 100003:
+	case 100001 <= i && i <= 100005:
 		i -= 100001
 		return _Code_name_1[_Code_index_1[i]:_Code_index_1[i+1]]
-	case 100101 <= i && i <= 100103:
+	case 100101 <= i && i <= 100104:
 		i -= 100101
 		return _Code_name_2[_Code_index_2[i]:_Code_index_2[i+1]]
 	case 110001 <= i && i <= 110008:
@@ -61,8 +73,13 @@ func (i Code) String() string {
 	case 110101 <= i && i <= 110103:
 		i -= 110101
 		return _Code_name_4[_Code_index_4[i]:_Code_index_4[i+1]]
-	case i == 110201:
-		return _Code_name_5
+	case 110201 <= i && i <= 110205:
+		i -= 110201
+		return _Code_name_5[_Code_index_5[i]:_Code_index_5[i+1]]
+	case i == 120001:
+		return _Code_name_6
+	case i == 120101:
+		return _Code_name_7
 	default:
 		return "Code(" + strconv.FormatInt(int64(i), 10) + ")"
 	}